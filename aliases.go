@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/montrey/navi/search"
+	"github.com/montrey/navi/store"
+)
+
+// aliasPrefix starts an alias lookup in the search input (":dots").
+const aliasPrefix = ":"
+
+// aliasQuery reports whether input is an alias lookup and returns the name typed so far.
+func aliasQuery(input string) (string, bool) {
+	return strings.CutPrefix(strings.TrimSpace(input), aliasPrefix)
+}
+
+// reservedNames are the subcommand names an alias can't take. It repeats the
+// keys of subcommands, which can't be read here: runAlias is one of them.
+var reservedNames = []string{"alias", "autotag", "export", "history", "import", "pin"}
+
+// validateAliasName rejects names that could not be typed as `navi <name>`
+// or `:<name>`, or that would be shadowed by a subcommand.
+func validateAliasName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("alias name is empty")
+	case strings.ContainsAny(name, " \t/"+string(filepath.Separator)):
+		return fmt.Errorf("alias name %q must not contain spaces or slashes", name)
+	case strings.ContainsAny(name[:1], "@-:"):
+		return fmt.Errorf("alias name %q must not start with @, - or :", name)
+	}
+	if slices.Contains(reservedNames, name) {
+		return fmt.Errorf("alias name %q is a navi command", name)
+	}
+	return nil
+}
+
+// matchAliases returns the paths of the aliases whose name starts with
+// prefix: the exact match first, then by name.
+func matchAliases(aliases map[string]string, prefix string) []string {
+	var names []string
+	for name := range aliases {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == prefix) != (names[j] == prefix) {
+			return names[i] == prefix
+		}
+		return names[i] < names[j]
+	})
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, aliases[name])
+	}
+	return paths
+}
+
+// aliasResults resolves an alias lookup to search results, bypassing fuzzy
+// search, and records the entries of the aliased paths.
+func (m model) aliasResults(name string) []search.Result {
+	aliases, _ := m.db.GetAliases()
+	paths := matchAliases(aliases, name)
+	results := make([]search.Result, len(paths))
+	for i, p := range paths {
+		results[i] = search.Result{Path: p}
+		if e, err := search.StatEntry(p, p); err == nil {
+			m.entries[p] = e
+		}
+	}
+	return results
+}
+
+// runAlias implements `navi alias ls|set|rm`.
+func runAlias(db store.Store, args []string) error {
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+
+	switch args[0] {
+	case "ls", "list":
+		aliases, err := db.GetAliases()
+		if err != nil {
+			return err
+		}
+		for _, name := range slices.Sorted(maps.Keys(aliases)) {
+			fmt.Printf("%-15s %s\n", name, aliases[name])
+		}
+		return nil
+
+	case "set":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: navi alias set <name> <path>")
+		}
+		name, path := args[1], expandHome(args[2])
+		if err := validateAliasName(name); err != nil {
+			return err
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if _, err := os.Stat(path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s does not exist\n", path)
+		}
+		if err := db.SetAlias(name, path); err != nil {
+			return err
+		}
+		fmt.Printf("%s -> %s\n", name, path)
+		return nil
+
+	case "rm", "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: navi alias rm <name>...")
+		}
+		for _, name := range args[1:] {
+			ok, err := db.RemoveAlias(name)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Fprintf(os.Stderr, "no alias: %s\n", name)
+				continue
+			}
+			fmt.Printf("Removed %s\n", name)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown alias command %q (want ls, set or rm)", args[0])
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/montrey/navi/store"
+)
+
+// autoTagRule tags directories automatically. A marker rule tags the nearest
+// directory containing a file named Pattern (globs allowed, e.g. "*.csproj");
+// a path rule (Pattern starting with "/" or "~") tags the directory Pattern
+// once anything below it is visited.
+type autoTagRule struct {
+	Pattern string
+	Tag     string
+}
+
+// isPath reports whether the rule matches by location rather than by marker file.
+func (r autoTagRule) isPath() bool {
+	return strings.HasPrefix(r.Pattern, "/") || strings.HasPrefix(r.Pattern, "~")
+}
+
+// root returns the directory the rule tags for a visit to dir: the rule's
+// own directory for a path rule, or the nearest directory at or above dir
+// containing the marker. Like project.FindTagFile, the marker search stops
+// at the repository root; the home directory and the filesystem root are
+// never tagged by a marker.
+func (r autoTagRule) root(dir string) (string, bool) {
+	if r.isPath() {
+		prefix := filepath.Clean(expandHome(r.Pattern))
+		return prefix, dir == prefix || strings.HasPrefix(dir, prefix+string(filepath.Separator))
+	}
+	home, _ := os.UserHomeDir()
+	for {
+		if dir == home || filepath.Dir(dir) == dir {
+			return "", false
+		}
+		if matches, _ := filepath.Glob(filepath.Join(dir, r.Pattern)); len(matches) > 0 {
+			return dir, true
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// parseAutoTagRules reads the auto_tags setting: "go.mod=go; package.json=node; ~/work=work".
+// A leading '@' on the tag is optional.
+func parseAutoTagRules(raw string) []autoTagRule {
+	var rules []autoTagRule
+	for _, part := range strings.Split(raw, ";") {
+		pattern, tag, ok := strings.Cut(part, "=")
+		pattern = strings.TrimSpace(pattern)
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "@")
+		if !ok || pattern == "" || tag == "" {
+			continue
+		}
+		rules = append(rules, autoTagRule{Pattern: pattern, Tag: tag})
+	}
+	return rules
+}
+
+// expandHome replaces a leading "~" with the home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + rest
+		}
+	}
+	return path
+}
+
+// autoTagDir returns the directory rules are evaluated against for path:
+// the path itself if it is a directory, otherwise its parent.
+func autoTagDir(path string) string {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return filepath.Dir(path)
+	}
+	return path
+}
+
+// autoTag is a tag added by a rule and the directory it was added to.
+type autoTag struct {
+	Tag string
+	Dir string
+}
+
+// applyAutoTags tags the root of every rule matching the directory of path
+// (see autoTagRule.root) and returns the tags that were newly added.
+func applyAutoTags(db store.Store, rules []autoTagRule, path string) ([]autoTag, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	dir := autoTagDir(path)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, nil
+	}
+	var added []autoTag
+	for _, r := range rules {
+		root, ok := r.root(dir)
+		if !ok || slices.Contains(added, autoTag{r.Tag, root}) {
+			continue
+		}
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		existing, err := db.GetTagsForPath(root)
+		if err != nil {
+			return added, err
+		}
+		if slices.Contains(existing, r.Tag) {
+			continue
+		}
+		if err := db.AddPathToTag(r.Tag, root); err != nil {
+			return added, err
+		}
+		added = append(added, autoTag{r.Tag, root})
+	}
+	return added, nil
+}
+
+// autoTagRules loads the configured rules.
+func autoTagRules(db store.Store) []autoTagRule {
+	raw, _ := db.GetSetting("auto_tags")
+	return parseAutoTagRules(raw)
+}
+
+// runAutotag implements `navi autotag ls|set|apply`.
+func runAutotag(db store.Store, args []string) error {
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+
+	switch args[0] {
+	case "ls", "list":
+		for _, r := range autoTagRules(db) {
+			fmt.Printf("%-30s @%s\n", r.Pattern, r.Tag)
+		}
+		return nil
+
+	case "set":
+		if len(args) != 2 {
+			return fmt.Errorf(`usage: navi autotag set "go.mod=go; package.json=node; ~/work=work"`)
+		}
+		return db.SetSetting("auto_tags", args[1])
+
+	case "apply":
+		flags := flag.NewFlagSet("autotag apply", flag.ContinueOnError)
+		dryRun := flags.Bool("dry-run", false, "Print the tags that would be added without adding them")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		rules := autoTagRules(db)
+		if len(rules) == 0 {
+			return fmt.Errorf(`no auto-tag rules; add some with navi autotag set "go.mod=go; ~/work=work"`)
+		}
+		history, err := db.GetHistory()
+		if err != nil {
+			return err
+		}
+
+		target := db
+		if *dryRun {
+			// Apply to a scratch copy of the tags so nothing is written
+			scratch := store.NewMemStore()
+			tagged, _ := db.GetTagsByPath()
+			for p, tags := range tagged {
+				for _, t := range tags {
+					_ = scratch.AddPathToTag(t, p)
+				}
+			}
+			target = scratch
+		}
+
+		total := 0
+		seen := make(map[string]bool)
+		for _, h := range history {
+			dir := autoTagDir(h.Path)
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			added, err := applyAutoTags(target, rules, dir)
+			if err != nil {
+				return err
+			}
+			for _, t := range added {
+				fmt.Printf("@%-15s %s\n", t.Tag, t.Dir)
+			}
+			total += len(added)
+		}
+		if *dryRun {
+			fmt.Printf("Would add %d tags\n", total)
+		} else {
+			fmt.Printf("Added %d tags\n", total)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown autotag command %q (want ls, set or apply)", args[0])
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/montrey/navi/store"
+)
+
+func TestParseAutoTagRules(t *testing.T) {
+	got := parseAutoTagRules(" go.mod=go; package.json = @node ;broken; ~/work=work; =x")
+	want := []autoTagRule{
+		{Pattern: "go.mod", Tag: "go"},
+		{Pattern: "package.json", Tag: "node"},
+		{Pattern: "~/work", Tag: "work"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestApplyAutoTags(t *testing.T) {
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	svc := filepath.Join(work, "svc")
+	pkg := filepath.Join(svc, "internal", "pkg")
+	if err := os.MkdirAll(pkg, 0755); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(pkg, "main.go")
+	for _, f := range []string{filepath.Join(svc, "go.mod"), main} {
+		if err := os.WriteFile(f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db := store.NewMemStore()
+	_ = db.SetSetting("auto_tags", "go.mod=go; *.json=node; "+work+"=work")
+
+	// A file deep in the project tags the directory holding the marker and
+	// the path rule's own directory, not the visited directory
+	if err := recordVisit(db, main, ""); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{svc: {"go"}, work: {"work"}}
+	if got, _ := db.GetTagsByPath(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Visiting more of the tree adds nothing
+	for _, dir := range []string{svc, filepath.Join(svc, "internal")} {
+		added, err := applyAutoTags(db, autoTagRules(db), dir)
+		if err != nil || len(added) != 0 {
+			t.Errorf("expected re-applying in %s to add nothing, got %v (%v)", dir, added, err)
+		}
+	}
+
+	// The marker search stops at the repository root
+	repo := filepath.Join(svc, "vendored")
+	_ = os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	if added, _ := applyAutoTags(db, []autoTagRule{{Pattern: "go.mod", Tag: "go"}}, repo); len(added) != 0 {
+		t.Errorf("expected no marker above the repository root, got %v", added)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/montrey/navi/store"
+	"github.com/montrey/navi/vcs"
+)
+
+// dirtyScope is the pseudo-tag (@dirty) that lists the changed files of the
+// repositories containing tagged paths and the current directory.
+const dirtyScope = "dirty"
+
+// gitScanLimit caps how many results, best first, get their git status looked up.
+const gitScanLimit = 1000
+
+// gitStatusMsg reports that the status of a refreshed repository changed.
+type gitStatusMsg struct{}
+
+// refreshGit runs git status in repos so the next search sees their state.
+// It only asks for a new search if some repository's state changed.
+func refreshGit(cache *vcs.Cache, repos []string) tea.Cmd {
+	return func() tea.Msg {
+		changed := false
+		for _, r := range repos {
+			if c, _ := cache.Refresh(r); c {
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		return gitStatusMsg{}
+	}
+}
+
+// gitStates returns the cached git state of the first gitScanLimit paths,
+// keyed like paths, and the repositories among them that need a refresh.
+// It never runs git itself.
+func (m model) gitStates(paths []string) (map[string]vcs.State, []string) {
+	states := make(map[string]vcs.State)
+	if m.git == nil {
+		return states, nil
+	}
+	var stale []string
+	seen := make(map[string]bool)
+	for i, p := range paths {
+		if i == gitScanLimit {
+			break
+		}
+		abs := resolveSelectedPath(p, m.currentDir)
+		repo, ok := m.git.RepoRoot(abs)
+		if !ok {
+			continue
+		}
+		if !seen[repo] {
+			seen[repo] = true
+			if !m.git.Fresh(repo) {
+				stale = append(stale, repo)
+			}
+		}
+		if s := m.git.Peek(abs); s.Dirty() {
+			states[p] = s
+		}
+	}
+	return states, stale
+}
+
+// loadDirtyFiles loads the @dirty scope: the changed files of every
+// repository holding a tagged path or dir.
+func loadDirtyFiles(db store.Store, cache *vcs.Cache, dir string) tea.Cmd {
+	return func() tea.Msg {
+		if cache == nil {
+			return filesLoadedMsg(nil)
+		}
+		tagged, _ := db.GetAllTaggedPaths()
+		var repos []string
+		seen := make(map[string]bool)
+		for _, p := range append([]string{dir}, tagged...) {
+			if repo, ok := cache.RepoRoot(p); ok && !seen[repo] {
+				seen[repo] = true
+				repos = append(repos, repo)
+			}
+		}
+		var files []string
+		for _, repo := range repos {
+			changed, err := cache.Changed(repo)
+			if err != nil {
+				continue
+			}
+			files = append(files, changed...)
+		}
+		return filesLoadedMsg(statEntries(files))
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/montrey/navi/search"
+	"github.com/montrey/navi/store"
+)
+
+// defaultMaxAge is the total frequency at which history starts aging,
+// like zoxide's _ZO_MAXAGE. Override with $NAVI_MAXAGE (0 disables aging).
+const defaultMaxAge = 10000
+
+func maxAge() int {
+	if v := os.Getenv("NAVI_MAXAGE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return defaultMaxAge
+}
+
+// recordVisit bumps the frecency of path, globally and for the context it
+// was visited from (if any), applies the auto-tag rules to it, records it as
+// a project if it is one. Aging is left to sweepCmd and `navi history prune`.
+func recordVisit(db store.Store, path, context string) error {
+	if err := db.UpdateFrecency(path); err != nil {
+		return err
+	}
+	if context != "" {
+		if err := db.UpdateContextFrecency(context, path); err != nil {
+			return err
+		}
+	}
+	if _, err := applyAutoTags(db, autoTagRules(db), path); err != nil {
+		return err
+	}
+	if dir := autoTagDir(path); search.IsProjectRoot(dir) {
+		if err := db.AddProjects([]string{dir}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visitContext returns the context visits made from dir are recorded under:
+// the nearest project root containing dir, or dir itself outside projects.
+func visitContext(dir string) string {
+	for d := dir; ; {
+		if search.IsProjectRoot(d) {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// deadHistoryAge is how long a missing path must have gone unvisited before
+// the background sweep forgets it. A path under an unmounted drive is missing
+// too, so recently used entries are kept until it is mounted again.
+const deadHistoryAge = 90 * 24 * time.Hour
+
+// sweepDeadHistory removes history entries whose path no longer exists and
+// that were last visited before cutoff. Paths that can't be stat'ed for
+// other reasons (permissions) are kept.
+func sweepDeadHistory(db store.Store, cutoff time.Time) (int, error) {
+	history, err := db.GetHistory()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, h := range history {
+		if !h.LastVisited.Before(cutoff) {
+			continue
+		}
+		if _, err := os.Lstat(h.Path); errors.Is(err, fs.ErrNotExist) {
+			if ok, err := db.RemoveHistory(h.Path); err != nil {
+				return removed, err
+			} else if ok {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// sweepDeadProjects forgets project roots that no longer exist. A root under
+// an unmounted drive is missing too, so only `navi history prune --dead`
+// runs it; ranking just skips missing roots.
+func sweepDeadProjects(db store.Store) (int, error) {
+	projects, err := db.GetProjects()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, p := range projects {
+		if _, err := os.Lstat(p); errors.Is(err, fs.ErrNotExist) {
+			if ok, err := db.RemoveProject(p); err != nil {
+				return removed, err
+			} else if ok {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// sweepCmd forgets history entries whose paths have been missing and
+// unvisited for deadHistoryAge and ages history past maxAge. The TUI runs it
+// in the background once the initial results are shown.
+func sweepCmd(db store.Store) tea.Cmd {
+	return func() tea.Msg {
+		_, _ = sweepDeadHistory(db, time.Now().Add(-deadHistoryAge))
+		_, _ = db.AgeHistory(maxAge())
+		return nil
+	}
+}
+
+// parseAge parses durations like "90d", "2w" or anything time.ParseDuration accepts.
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			days, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(days) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// runHistory implements `navi history ls|rm|prune`.
+func runHistory(db store.Store, args []string) error {
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+
+	switch args[0] {
+	case "ls", "list":
+		history, err := db.GetHistory()
+		if err != nil {
+			return err
+		}
+		for _, h := range history {
+			fmt.Printf("%6d  %s  %s\n", h.Frequency, h.LastVisited.Local().Format("2006-01-02 15:04"), h.Path)
+		}
+		return nil
+
+	case "rm", "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: navi history rm <path>...")
+		}
+		for _, p := range args[1:] {
+			if abs, err := filepath.Abs(p); err == nil {
+				p = abs
+			}
+			ok, err := db.RemoveHistory(p)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Fprintf(os.Stderr, "not in history: %s\n", p)
+				continue
+			}
+			fmt.Printf("Removed %s\n", p)
+		}
+		return nil
+
+	case "prune":
+		flags := flag.NewFlagSet("history prune", flag.ContinueOnError)
+		olderThan := flags.String("older-than", "", "Remove entries not visited within this age (e.g. 90d, 2w, 12h)")
+		dead := flags.Bool("dead", false, "Remove entries and projects whose path no longer exists")
+		maxTotal := flags.Int("max-age", maxAge(), "Age frecency scores once their total exceeds this")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		// Plain `prune` ages; with other flags only an explicit --max-age does
+		age := flags.NFlag() == 0
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "max-age" {
+				age = true
+			}
+		})
+
+		if *olderThan != "" {
+			age, err := parseAge(*olderThan)
+			if err != nil {
+				return err
+			}
+			n, err := db.PruneHistory(time.Now().Add(-age))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d entries older than %s\n", n, *olderThan)
+		}
+		if *dead {
+			n, err := sweepDeadHistory(db, time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d entries for missing paths\n", n)
+			if n, err = sweepDeadProjects(db); err != nil {
+				return err
+			}
+			if n > 0 {
+				fmt.Printf("Forgot %d missing projects\n", n)
+			}
+		}
+		if age {
+			n, err := db.AgeHistory(*maxTotal)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				fmt.Printf("Aged out %d low-score entries\n", n)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown history command %q (want ls, rm or prune)", args[0])
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/montrey/navi/store"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for in, want := range tests {
+		got, err := parseAge(in)
+		if err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseAge("xd"); err == nil {
+		t.Errorf("expected error for invalid age")
+	}
+}
+
+func TestSweepDeadHistory(t *testing.T) {
+	dir := t.TempDir()
+	alive := filepath.Join(dir, "alive")
+	if err := os.Mkdir(alive, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gone, unmounted := filepath.Join(dir, "gone"), filepath.Join(dir, "unmounted")
+
+	db := store.NewMemStore()
+	now := time.Now()
+	db.Now = func() time.Time { return now.Add(-100 * 24 * time.Hour) }
+	_ = db.UpdateFrecency(alive)
+	_ = db.UpdateFrecency(gone)
+	// Missing but visited recently, like a path under an unmounted drive
+	db.Now = func() time.Time { return now }
+	_ = db.UpdateFrecency(unmounted)
+
+	n, err := sweepDeadHistory(db, now.Add(-deadHistoryAge))
+	if err != nil || n != 1 {
+		t.Fatalf("expected one dead entry removed, got %d (%v)", n, err)
+	}
+	history, _ := db.GetHistory()
+	if len(history) != 2 || slices.ContainsFunc(history, func(h store.HistoryItem) bool { return h.Path == gone }) {
+		t.Errorf("expected %s and %s to remain, got %v", alive, unmounted, history)
+	}
+
+	// `prune --dead` doesn't wait
+	if n, _ := sweepDeadHistory(db, now.Add(time.Second)); n != 1 {
+		t.Errorf("expected %s removed without the grace period, got %d", unmounted, n)
+	}
+}
+
+func TestSweepDeadProjects(t *testing.T) {
+	dir := t.TempDir()
+	alive := filepath.Join(dir, "alive")
+	if err := os.Mkdir(alive, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gone := filepath.Join(dir, "gone")
+
+	db := store.NewMemStore()
+	_ = db.AddProjects([]string{alive, gone})
+
+	// Ranking skips the vanished project without forgetting it
+	if got := rankedProjects(db); len(got) != 1 || got[0] != alive {
+		t.Errorf("expected only %s ranked, got %v", alive, got)
+	}
+	if projects, _ := db.GetProjects(); len(projects) != 2 {
+		t.Errorf("expected ranking to leave the store alone, got %v", projects)
+	}
+
+	n, err := sweepDeadProjects(db)
+	if err != nil || n != 1 {
+		t.Fatalf("expected one dead project removed, got %d (%v)", n, err)
+	}
+	if projects, _ := db.GetProjects(); len(projects) != 1 || projects[0] != alive {
+		t.Errorf("expected only %s to remain, got %v", alive, projects)
+	}
+}
+
+func TestPruneAgesOnlyWhenAsked(t *testing.T) {
+	t.Setenv("NAVI_MAXAGE", "10")
+	db := store.NewMemStore()
+	_ = db.MergeHistory([]store.HistoryItem{{Path: "/hot", Frequency: 100, LastVisited: time.Now()}})
+	frequency := func() int {
+		history, _ := db.GetHistory()
+		return history[0].Frequency
+	}
+
+	if err := runHistory(db, []string{"prune", "--older-than", "90d"}); err != nil {
+		t.Fatal(err)
+	}
+	if f := frequency(); f != 100 {
+		t.Errorf("expected --older-than to leave scores alone, got %d", f)
+	}
+	if err := runHistory(db, []string{"prune"}); err != nil {
+		t.Fatal(err)
+	}
+	if f := frequency(); f != 9 {
+		t.Errorf("expected a plain prune to age to 9, got %d", f)
+	}
+}
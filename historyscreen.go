@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/search"
+	"github.com/montrey/navi/store"
+)
+
+// historyScreen is the state of the history screen (Ctrl+R): every visited
+// path with when it was last visited, how often, and whether it still exists.
+type historyScreen struct {
+	items     []store.HistoryItem // Most recent first
+	missing   map[string]bool     // Paths that no longer exist
+	shown     []store.HistoryItem // items matching the filter, best first
+	selected  int
+	offset    int // First visible row of shown
+	filter    textinput.Model
+	forgotten map[string]bool // Paths forgotten here, dropped from the results on Esc
+	err       error
+}
+
+// openHistory switches to the history screen with an empty filter.
+func (m model) openHistory() model {
+	filter := textinput.New()
+	filter.Placeholder = "Filter..."
+	filter.CharLimit = 156
+	filter.Focus()
+	m.historyScreen = historyScreen{filter: filter, forgotten: make(map[string]bool)}
+	m.reloadHistory()
+	m.mode = modeHistory
+	return m
+}
+
+// reloadHistory rereads history and reapplies the filter.
+func (m *model) reloadHistory() {
+	hs := &m.historyScreen
+	hs.items, hs.err = m.db.GetHistory()
+	hs.missing = make(map[string]bool)
+	for _, h := range hs.items {
+		if _, err := os.Lstat(h.Path); errors.Is(err, fs.ErrNotExist) {
+			hs.missing[h.Path] = true
+		}
+	}
+	hs.applyFilter()
+}
+
+// applyFilter fuzzy-matches the entries against the filter, keeping recency
+// order while it is empty.
+func (hs *historyScreen) applyFilter() {
+	query := strings.TrimSpace(hs.filter.Value())
+	if query == "" {
+		hs.shown = hs.items
+	} else {
+		byPath := make(map[string]store.HistoryItem, len(hs.items))
+		paths := make([]string, len(hs.items))
+		for i, h := range hs.items {
+			byPath[h.Path] = h
+			paths[i] = h.Path
+		}
+		hs.shown = nil
+		for _, res := range search.FuzzyHierarchical(paths, query) {
+			hs.shown = append(hs.shown, byPath[res.Path])
+		}
+	}
+	hs.selected = clampIndex(hs.selected, len(hs.shown))
+}
+
+// historyRows is how many entries fit on the history screen.
+func (m model) historyRows() int {
+	// Title, help, filter and status lines
+	if rows := m.height - 4; rows > 0 {
+		return rows
+	}
+	return 20
+}
+
+func (m model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	hs := &m.historyScreen
+	hs.err = nil
+
+	switch msg.String() {
+	case "esc":
+		// Reloading the badges searches again
+		m.mode = modeBrowse
+		if len(hs.forgotten) > 0 {
+			m = m.dropForgotten(hs.forgotten)
+		}
+		return m, m.loadBadges()
+	case "up":
+		if hs.selected > 0 {
+			hs.selected--
+		}
+	case "down":
+		if hs.selected < len(hs.shown)-1 {
+			hs.selected++
+		}
+	case "ctrl+x", "delete":
+		if hs.selected < len(hs.shown) {
+			path := hs.shown[hs.selected].Path
+			if _, hs.err = m.db.RemoveHistory(path); hs.err == nil {
+				hs.forgotten[path] = true
+			}
+			m.reloadHistory()
+		}
+	case "enter":
+		if hs.selected < len(hs.shown) {
+			path := hs.shown[hs.selected].Path
+			if hs.missing[path] {
+				hs.err = fmt.Errorf("%s no longer exists; Ctrl+X forgets it", path)
+				return m, nil
+			}
+			return m.openPath(path)
+		}
+	default:
+		old := hs.filter.Value()
+		var cmd tea.Cmd
+		hs.filter, cmd = hs.filter.Update(msg)
+		if hs.filter.Value() != old {
+			hs.selected, hs.offset = 0, 0
+			hs.applyFilter()
+		}
+		return m, cmd
+	}
+
+	// Keep the selection on screen
+	rows := m.historyRows()
+	if hs.selected < hs.offset {
+		hs.offset = hs.selected
+	} else if hs.selected >= hs.offset+rows {
+		hs.offset = hs.selected - rows + 1
+	}
+	return m, nil
+}
+
+// dropForgotten removes paths forgotten on the history screen from the
+// results, unless they are still tagged or pinned.
+func (m model) dropForgotten(forgotten map[string]bool) model {
+	var kept []string
+	for _, p := range m.historyFiles {
+		if !forgotten[p] || len(m.pathTags[p]) > 0 || slices.Contains(m.pins, p) {
+			kept = append(kept, p)
+		}
+	}
+	for p := range forgotten {
+		delete(m.historyPaths, p)
+	}
+	m.setHistoryFiles(kept)
+	return m
+}
+
+func (m model) historyView() string {
+	hs := m.historyScreen
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	missingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+
+	title := lipgloss.NewStyle().Bold(true).Render("History") + dim.Render(fmt.Sprintf("  %d of %d", len(hs.shown), len(hs.items)))
+	help := dim.Render("Type to filter • Enter: open • Ctrl+X/Del: forget • Esc: back")
+
+	var lines []string
+	if len(hs.shown) == 0 {
+		lines = append(lines, "(no entries)")
+	}
+	now := time.Now()
+	offset := min(hs.offset, max(len(hs.shown)-1, 0))
+	for i := offset; i < len(hs.shown) && len(lines) < m.historyRows(); i++ {
+		h := hs.shown[i]
+		prefix := "  "
+		if i == hs.selected {
+			prefix = "> "
+		}
+		line := prefix + detailStyle.Render(fmt.Sprintf("%8s %5d×  ", relativeTime(h.LastVisited, now), h.Frequency)) + h.Path
+		if hs.missing[h.Path] {
+			line += " " + missingStyle.Render("(missing)")
+		}
+		lines = append(lines, line)
+	}
+
+	status := ""
+	if hs.err != nil {
+		status = missingStyle.Render(hs.err.Error())
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		help,
+		hs.filter.View(),
+		strings.Join(lines, "\n"),
+		status,
+	)
+}
+
+// relativeTime formats how long before now t was: "just now", "5m ago",
+// "2h ago", "3d ago", "2w ago", then the date.
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d < 5*7*24*time.Hour:
+		return fmt.Sprintf("%dw ago", int(d.Hours()/(7*24)))
+	}
+	return t.Local().Format("2006-01-02")
+}
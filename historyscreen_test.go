@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/montrey/navi/store"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 5, 6, 12, 0, 0, 0, time.Local)
+	cases := map[time.Duration]string{
+		10 * time.Second:    "just now",
+		5 * time.Minute:     "5m ago",
+		2 * time.Hour:       "2h ago",
+		3 * 24 * time.Hour:  "3d ago",
+		15 * 24 * time.Hour: "2w ago",
+		90 * 24 * time.Hour: "2024-02-06",
+	}
+	for ago, want := range cases {
+		if got := relativeTime(now.Add(-ago), now); got != want {
+			t.Errorf("%v ago: expected %q, got %q", ago, want, got)
+		}
+	}
+}
+
+func TestHistoryScreen(t *testing.T) {
+	db := store.NewMemStore()
+	dir := t.TempDir()
+	gone := filepath.Join(dir, "gone")
+	_ = db.UpdateFrecency(dir)
+	_ = db.UpdateFrecency(gone)
+	_ = db.UpdateFrecency("/srv/api")
+
+	m := initialModel(db, appConfig{})
+	updated, _ := m.Update(loadInitialFiles(db, "")())
+	m = updated.(model).openHistory()
+	hs := m.historyScreen
+	if len(hs.shown) != 3 || !hs.missing[gone] || hs.missing[dir] {
+		t.Fatalf("expected 3 entries with %s missing, got %v (missing %v)", gone, hs.shown, hs.missing)
+	}
+
+	key := func(m model, msg tea.KeyMsg) model {
+		updated, _ := m.updateHistory(msg)
+		return updated.(model)
+	}
+	m = key(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("gone")})
+	if len(m.historyScreen.shown) != 1 || m.historyScreen.shown[0].Path != gone {
+		t.Fatalf("expected the filter to keep only %s, got %v", gone, m.historyScreen.shown)
+	}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != modeHistory || m.selectedPath != "" || m.historyScreen.err == nil {
+		t.Errorf("expected Enter on a missing entry to be refused, got mode %v, selected %q", m.mode, m.selectedPath)
+	}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyCtrlX})
+	if history, _ := db.GetHistory(); len(history) != 2 {
+		t.Errorf("expected the entry to be forgotten, got %v", history)
+	}
+	if len(m.historyScreen.shown) != 0 {
+		t.Errorf("expected no entries left matching the filter, got %v", m.historyScreen.shown)
+	}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != modeBrowse {
+		t.Errorf("expected Esc to return to browsing")
+	}
+	if slices.Contains(m.allFiles, gone) || m.historyPaths[gone] {
+		t.Errorf("expected %s to be dropped from the results, got %v", gone, m.allFiles)
+	}
+	if !slices.Contains(m.allFiles, dir) {
+		t.Errorf("expected %s to stay in the results, got %v", dir, m.allFiles)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/montrey/navi/store"
+)
+
+// historyParser reads another jumper's database into history items.
+// now is used for formats that don't record a last visit time.
+type historyParser func(r io.Reader, now time.Time) ([]store.HistoryItem, error)
+
+// untimedVisitAge is how long ago entries from formats without visit times
+// (zoxide, autojump) are stamped as last visited: old enough for the lowest
+// frecency weight, so imported counts don't outrank real recent visits.
+const untimedVisitAge = 30 * 24 * time.Hour
+
+var historyParsers = map[string]historyParser{
+	"zoxide":   parseZoxide,
+	"autojump": parseAutojump,
+	"z":        parseZ,
+	"fasd":     parseZ, // fasd uses z's path|rank|time format
+}
+
+// rankToFrequency maps a fractional rank/score onto a visit count (at least 1).
+func rankToFrequency(rank float64) int {
+	if rank < 1 || math.IsNaN(rank) {
+		return 1
+	}
+	return int(math.Round(rank))
+}
+
+// parseZoxide reads `zoxide query --list --score` output: "<score> <path>".
+func parseZoxide(r io.Reader, now time.Time) ([]store.HistoryItem, error) {
+	var items []store.HistoryItem
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		scoreStr, path, ok := strings.Cut(text, " ")
+		score, err := strconv.ParseFloat(scoreStr, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("line %d: expected \"<score> <path>\"", line)
+		}
+		items = append(items, store.HistoryItem{
+			Path:        strings.TrimSpace(path),
+			Frequency:   rankToFrequency(score),
+			LastVisited: now.Add(-untimedVisitAge),
+		})
+	}
+	return items, scanner.Err()
+}
+
+// parseAutojump reads autojump.txt: "<weight>\t<path>".
+func parseAutojump(r io.Reader, now time.Time) ([]store.HistoryItem, error) {
+	var items []store.HistoryItem
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		weightStr, path, ok := strings.Cut(text, "\t")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("line %d: expected \"<weight>\\t<path>\"", line)
+		}
+		items = append(items, store.HistoryItem{
+			Path:        path,
+			Frequency:   rankToFrequency(weight),
+			LastVisited: now.Add(-untimedVisitAge),
+		})
+	}
+	return items, scanner.Err()
+}
+
+// parseZ reads z/fasd data files: "<path>|<rank>|<unix time>".
+// The path itself may contain '|', so fields are split from the right.
+func parseZ(r io.Reader, _ time.Time) ([]store.HistoryItem, error) {
+	var items []store.HistoryItem
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		last := strings.LastIndex(text, "|")
+		if last < 0 {
+			return nil, fmt.Errorf("line %d: expected \"<path>|<rank>|<time>\"", line)
+		}
+		mid := strings.LastIndex(text[:last], "|")
+		if mid < 0 {
+			return nil, fmt.Errorf("line %d: expected \"<path>|<rank>|<time>\"", line)
+		}
+		rank, err := strconv.ParseFloat(text[mid+1:last], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rank: %w", line, err)
+		}
+		ts, err := strconv.ParseInt(text[last+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid timestamp: %w", line, err)
+		}
+		items = append(items, store.HistoryItem{
+			Path:        text[:mid],
+			Frequency:   rankToFrequency(rank),
+			LastVisited: time.Unix(ts, 0),
+		})
+	}
+	return items, scanner.Err()
+}
+
+// defaultHistoryFile returns where the given tool keeps its data by default.
+func defaultHistoryFile(tool string) (string, error) {
+	home, _ := os.UserHomeDir()
+	switch tool {
+	case "autojump":
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(dataHome, "autojump", "autojump.txt"), nil
+	case "z":
+		if p := os.Getenv("_Z_DATA"); p != "" {
+			return p, nil
+		}
+		return filepath.Join(home, ".z"), nil
+	case "fasd":
+		if p := os.Getenv("_FASD_DATA"); p != "" {
+			return p, nil
+		}
+		return filepath.Join(home, ".fasd"), nil
+	}
+	return "", fmt.Errorf("%s has no readable default database; save `zoxide query --list --score` to a file (or pipe it and pass -)", tool)
+}
+
+// importHistory parses a foreign jumper database and merges it into history.
+func importHistory(db store.Store, tool, file string) (int, error) {
+	parse, ok := historyParsers[tool]
+	if !ok {
+		return 0, fmt.Errorf("unknown source %q (want zoxide, autojump, z or fasd)", tool)
+	}
+	if file == "" {
+		var err error
+		if file, err = defaultHistoryFile(tool); err != nil {
+			return 0, err
+		}
+	}
+
+	r, err := openInput(file)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	items, err := parse(r, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := db.MergeHistory(items); err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// openInput opens file for reading, or stdin for "-".
+func openInput(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
+
+// runImport implements `navi import [--replace] <state.json>` and
+// `navi import --from zoxide|autojump|z|fasd [file]`.
+func runImport(db store.Store, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	from := flags.String("from", "", "Import history from zoxide|autojump|z|fasd")
+	replace := flags.Bool("replace", false, "Clear tags, tag definitions, history, context history, query picks, pins, aliases and settings before importing (state.json only)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *replace && *from != "" {
+		return fmt.Errorf("usage: --replace only applies to state.json; --from always merges")
+	}
+
+	if *from == "" {
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: navi import [--replace] <state.json|->  or  navi import --from zoxide|autojump|z|fasd [file]")
+		}
+		r, err := openInput(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		var state store.State
+		if err := json.NewDecoder(r).Decode(&state); err != nil {
+			return fmt.Errorf("%s: %w", flags.Arg(0), err)
+		}
+		for _, name := range slices.Sorted(maps.Keys(state.Aliases)) {
+			if err := validateAliasName(name); err != nil {
+				return fmt.Errorf("%s: %w", flags.Arg(0), err)
+			}
+		}
+		if err := db.ImportState(state, *replace); err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d tags, %d history entries and %d settings\n",
+			len(state.Tags), len(state.History), len(state.Settings))
+		return nil
+	}
+
+	n, err := importHistory(db, *from, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d entries from %s\n", n, *from)
+	return nil
+}
+
+// runExport implements `navi export`, writing the whole state as JSON to stdout.
+func runExport(db store.Store, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: navi export > state.json")
+	}
+	state, err := db.ExportState()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(state)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/montrey/navi/store"
+)
+
+func TestHistoryParsers(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	untimed := now.Add(-untimedVisitAge)
+
+	tests := []struct {
+		name  string
+		parse historyParser
+		input string
+		want  []store.HistoryItem
+	}{
+		{
+			name:  "zoxide",
+			parse: parseZoxide,
+			input: "  12.5 /home/user/work\n   0.3 /home/user/with space\n",
+			want: []store.HistoryItem{
+				{Path: "/home/user/work", Frequency: 13, LastVisited: untimed},
+				{Path: "/home/user/with space", Frequency: 1, LastVisited: untimed},
+			},
+		},
+		{
+			name:  "autojump",
+			parse: parseAutojump,
+			input: "22.36\t/home/user/work\n10.0\t/tmp\n",
+			want: []store.HistoryItem{
+				{Path: "/home/user/work", Frequency: 22, LastVisited: untimed},
+				{Path: "/tmp", Frequency: 10, LastVisited: untimed},
+			},
+		},
+		{
+			name:  "z",
+			parse: parseZ,
+			input: "/home/user/work|42|1700000000\n/home/user/a|b|3.5|1700000100\n",
+			want: []store.HistoryItem{
+				{Path: "/home/user/work", Frequency: 42, LastVisited: time.Unix(1700000000, 0)},
+				{Path: "/home/user/a|b", Frequency: 4, LastVisited: time.Unix(1700000100, 0)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(strings.NewReader(tt.input), now)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d items, got %v", len(tt.want), got)
+			}
+			for i := range tt.want {
+				if got[i].Path != tt.want[i].Path || got[i].Frequency != tt.want[i].Frequency || !got[i].LastVisited.Equal(tt.want[i].LastVisited) {
+					t.Errorf("item %d: expected %+v, got %+v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+
+	if _, err := parseZ(strings.NewReader("no separators\n"), now); err == nil {
+		t.Errorf("expected error for malformed z line")
+	}
+}
+
+func TestImportMergesHistory(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.UpdateFrecency("/home/user/work")
+
+	items, _ := parseZ(strings.NewReader("/home/user/work|5|1700000000\n/home/user/new|2|1700000000\n"), time.Now())
+	if err := db.MergeHistory(items); err != nil {
+		t.Fatal(err)
+	}
+
+	history, _ := db.GetHistory()
+	freq := make(map[string]int)
+	for _, h := range history {
+		freq[h.Path] = h.Frequency
+	}
+	if freq["/home/user/work"] != 5 || freq["/home/user/new"] != 2 {
+		t.Errorf("expected merged frequencies work=5 new=2, got %v", freq)
+	}
+}
+
+func TestImportReplaceNeedsStateFile(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.UpdateFrecency("/home/user/work")
+	if err := runImport(db, []string{"--replace", "--from", "z", "/nonexistent"}); err == nil || !strings.Contains(err.Error(), "--replace") {
+		t.Errorf("expected --replace with --from to be a usage error, got %v", err)
+	}
+	if history, _ := db.GetHistory(); len(history) != 1 {
+		t.Errorf("expected history untouched, got %v", history)
+	}
+}
@@ -1,39 +1,57 @@
 package main
 
 import (
-	"database/sql"
 	"flag"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/montrey/navi/project"
 	"github.com/montrey/navi/search"
 	"github.com/montrey/navi/store"
 	"github.com/montrey/navi/ui"
+	"github.com/montrey/navi/vcs"
 )
 
 type model struct {
-	db           *sql.DB
+	db           store.Store
 	input        textinput.Model
 	tree         ui.TreeModel
+	list         ui.ListModel
 	currentDir   string
 	allFiles     []string // Cache of all files in current loop (local or tag)
 	historyFiles []string // Files from history/tags (initial load)
 	currentDirFiles []string // Files from current directory
 	historyPaths map[string]bool // Set of paths that are from history
-	activeTag    string   // Currently active tag (empty if local)
+	entries      map[string]search.Entry // Path -> typed filesystem entry
+	frecency     map[string]float64  // Absolute path -> frecency score (list badges)
+	context      string              // Project or directory visits are recorded from (see visitContext)
+	contextFrecency map[string]float64 // Absolute path -> frecency of visits from context
+	pathTags     map[string][]string // Absolute path -> tag names (list badges)
+	tagDefs      map[string]store.TagDef // Tag name -> description, color, icon, action
+	git          *vcs.Cache // Git status per repository; nil if git is not installed
+	knownTags    []string // Stored and project tag names, for completion
+	tagCompletions []string // Tags matching the partially typed @tag
+	tagCompletion  int      // Highlighted completion
+	unknownTags  []string // Tags in the active query with no stored or project paths
+	tagQueryErr  error    // Why the active query can't be searched (see pseudoScopeErr)
+	actionBeforeTag string // Action to restore when leaving a tag with its own default action
+	activeTag    string   // Active tag query as tagQuery.String() (empty if local)
+	tagQuery     store.TagQuery // Active tag filter: @a @b, @a,@b, -@c
 	selectedPath string
 	width        int
 	height       int
-	err          error
+	err          error // Shown in place of the shortcuts (e.g. a broken .navi.toml)
 	isInitialLoad bool // Track if this is the initial load
 	currentDirLoaded bool // Track if current directory files have been loaded
 	mode         viewMode
@@ -50,10 +68,34 @@ type model struct {
 	tagList      []string
 	tagSelected  int
 	tagEditing   bool
+	tagDefStep   int // 0=not editing, 1=description, 2=color, 3=icon, 4=action
+	tagDef       store.TagDef // Definition being edited
 	tagInput     textinput.Model
+	tagErr       error // Invalid name typed on the tags screen
+	tagManager   tagManager
+	pins         []string // Pinned paths in the user's order; Alt+1-9 jump to the first nine
+	pinScreen    pinScreen
+	historyScreen historyScreen
+	dirBack      []string // Roots to return to with Alt+Left
+	dirForward   []string // Roots to return to with Alt+Right
+	crumbFocus   bool     // Choosing a breadcrumb segment with the keyboard (Ctrl+B)
+	crumbIndex   int      // Selected segment of ui.Crumbs(currentDir) while crumbFocus
+	lastClickPath string    // Result under the previous click (double-click detection)
+	lastClickTime time.Time
 }
 
-type filesLoadedMsg []string
+// subcommands maps the first positional argument to a CLI command.
+// Anything else is treated as a search query.
+var subcommands = map[string]func(db store.Store, args []string) error{
+	"history": runHistory,
+	"import":  runImport,
+	"export":  runExport,
+	"autotag": runAutotag,
+	"pin":     runPin,
+	"alias":   runAlias,
+}
+
+type filesLoadedMsg []search.Entry
 type searchDoneMsg []search.Result
 
 type viewMode int
@@ -62,6 +104,9 @@ const (
 	modeBrowse viewMode = iota
 	modeConfig
 	modeTags
+	modeTagManager
+	modePins
+	modeHistory
 )
 
 type appConfig struct {
@@ -70,41 +115,60 @@ type appConfig struct {
 	ExplorerCmd   string
 	EditorCmd     string
 	CustomActions string // Format: name=cmd; name2=cmd2
+	View          string // "tree" (Miller columns) or "list" (flat ranked list)
+	AutoTags      string // Format: go.mod=go; package.json=node; ~/work=work
 }
 
-// loadInitialFiles loads recent history + tagged paths for initial app load
-func loadInitialFiles(db *sql.DB) tea.Cmd {
+// listLimit caps how many results the flat list view renders.
+const listLimit = 200
+
+// loadInitialFiles loads pins, recent history and tagged paths for initial
+// app load. Pins come first in their order, then tagged paths, then paths
+// visited from context.
+func loadInitialFiles(db store.Store, context string) tea.Cmd {
 	return func() tea.Msg {
 		// Get recent history (last 100 items) and tagged paths
-		recentHistory, _ := store.GetRecentHistory(db, 100)
-		tagged, _ := store.GetAllTaggedPaths(db)
+		recentHistory, _ := db.GetRecentHistory(100)
+		tagged, _ := db.GetAllTaggedPaths()
+		contextHistory, _ := db.GetContextHistory(context)
+		pins, _ := db.GetPins()
 
 		// Combine recent history paths and tagged paths
 		pathSet := make(map[string]bool)
 		var files []string
 
-		// Add recent history paths
-		for _, h := range recentHistory {
+		// Add paths visited from the context, then recent history paths
+		for _, h := range append(contextHistory, recentHistory...) {
 			if !pathSet[h.Path] {
 				pathSet[h.Path] = true
 				files = append(files, h.Path)
 			}
 		}
 
-		// Add tagged paths
-		for _, p := range tagged {
+		// Add pinned and tagged paths
+		for _, p := range append(pins, tagged...) {
 			if !pathSet[p] {
 				pathSet[p] = true
 				files = append(files, p)
 			}
 		}
 
-		// Sort: Tagged > Recent > Alpha
+		// Sort: Pinned > Tagged > Context frecency > Recent > Alpha
+		pinPosition := make(map[string]int)
+		for i, p := range pins {
+			pinPosition[p] = i + 1
+		}
 		taggedSet := make(map[string]bool)
 		for _, p := range tagged {
 			taggedSet[p] = true
 		}
 
+		now := time.Now()
+		contextScore := make(map[string]float64)
+		for _, h := range contextHistory {
+			contextScore[h.Path] = h.Frecency(now)
+		}
+
 		recency := make(map[string]int)
 		for _, h := range recentHistory {
 			recency[h.Path] = int(h.LastVisited.Unix())
@@ -114,7 +178,17 @@ func loadInitialFiles(db *sql.DB) tea.Cmd {
 			p1 := files[i]
 			p2 := files[j]
 
-			// 1. Tagged?
+			// 1. Pinned? In pin order
+			pin1 := pinPosition[p1]
+			pin2 := pinPosition[p2]
+			if pin1 != pin2 {
+				if pin1 == 0 || pin2 == 0 {
+					return pin2 == 0
+				}
+				return pin1 < pin2
+			}
+
+			// 2. Tagged?
 			t1 := taggedSet[p1]
 			t2 := taggedSet[p2]
 			if t1 && !t2 {
@@ -124,31 +198,49 @@ func loadInitialFiles(db *sql.DB) tea.Cmd {
 				return false
 			}
 
-			// 2. Recent?
+			// 3. Visited from this context?
+			c1 := contextScore[p1]
+			c2 := contextScore[p2]
+			if c1 != c2 {
+				return c1 > c2
+			}
+
+			// 4. Recent?
 			r1 := recency[p1]
 			r2 := recency[p2]
 			if r1 != r2 {
 				return r1 > r2 // Standard desc timestamp
 			}
 
-			// 3. Alphabetical
+			// 5. Alphabetical
 			return p1 < p2
 		})
 
-		return filesLoadedMsg(files)
+		return filesLoadedMsg(statEntries(files))
 	}
 }
 
-func loadFiles(db *sql.DB, root string) tea.Cmd {
+// statEntries stats absolute paths (history, tags) into typed entries.
+// Paths that no longer exist keep TypeUnknown.
+func statEntries(paths []string) []search.Entry {
+	entries := make([]search.Entry, len(paths))
+	for i, p := range paths {
+		entries[i], _ = search.StatEntry(p, p)
+	}
+	return entries
+}
+
+func loadFiles(db store.Store, root string) tea.Cmd {
 	return func() tea.Msg {
 		files, err := search.Walk(root)
 		if err != nil {
 			return filesLoadedMsg(nil)
 		}
+		recordProjects(db, root, files)
 
 		// Default Prioritization
-		tagged, _ := store.GetAllTaggedPaths(db)
-		history, _ := store.GetHistory(db)
+		tagged, _ := db.GetAllTaggedPaths()
+		history, _ := db.GetHistory()
 
 		// Sort: Tagged > Recent > Current Dir (lower priority) > Alpha
 		// 1. Create Lookup Maps
@@ -171,14 +263,14 @@ func loadFiles(db *sql.DB, root string) tea.Cmd {
 		// Mark paths from current directory (give them lower priority)
 		isCurrentDir := make(map[string]bool)
 		for _, f := range files {
-			if strings.HasPrefix(f, root+string(filepath.Separator)) || f == root {
-				isCurrentDir[f] = true
+			if strings.HasPrefix(f.Path, root+string(filepath.Separator)) || f.Path == root {
+				isCurrentDir[f.Path] = true
 			}
 		}
 
 		sort.SliceStable(files, func(i, j int) bool {
-			p1 := files[i]
-			p2 := files[j]
+			p1 := files[i].Path
+			p2 := files[j].Path
 
 			// 1. Tagged?
 			t1 := isTagged[p1]
@@ -215,13 +307,116 @@ func loadFiles(db *sql.DB, root string) tea.Cmd {
 	}
 }
 
-func loadTagFiles(db *sql.DB, tag string) tea.Cmd {
+// loadTagFiles loads a tag scope: the stored paths matching q plus those the
+// project tag file (.navi.toml/.navi.json) above dir makes match, followed by
+// the contents of every matching directory.
+func loadTagFiles(db store.Store, git *vcs.Cache, dir string, q store.TagQuery) tea.Cmd {
+	switch q.String() {
+	case projectsScope:
+		return loadProjectFiles(db)
+	case dirtyScope:
+		return loadDirtyFiles(db, git, dir)
+	}
+	if pseudoScopeErr(q) != nil {
+		// Reported by setTagQuery
+		return func() tea.Msg { return filesLoadedMsg(nil) }
+	}
 	return func() tea.Msg {
-		paths, err := store.GetPathsForTag(db, tag)
+		paths, err := db.GetPathsForTagQuery(q)
 		if err != nil {
 			return filesLoadedMsg(nil)
 		}
-		return filesLoadedMsg(paths)
+
+		// Paths with project tags match on their stored and project tags
+		// combined, so a project tag can add a path or exclude a stored one.
+		// A broken project tag file leaves the stored tags; loadBadges
+		// reports the error once the results arrive
+		projectTags, _ := project.Tags(dir)
+		if len(projectTags) == 0 {
+			return filesLoadedMsg(search.WalkRoots(paths))
+		}
+		byPath := make(map[string][]string)
+		for tag, tagPaths := range projectTags {
+			for _, p := range tagPaths {
+				byPath[p] = append(byPath[p], tag)
+			}
+		}
+		stored, _ := db.GetTagsByPath()
+		matches := func(p string) bool {
+			return q.Match(append(slices.Clone(stored[p]), byPath[p]...))
+		}
+		paths = slices.DeleteFunc(paths, func(p string) bool {
+			return len(byPath[p]) > 0 && !matches(p)
+		})
+		seen := make(map[string]bool, len(paths))
+		for _, p := range paths {
+			seen[p] = true
+		}
+		for _, p := range slices.Sorted(maps.Keys(byPath)) {
+			if !seen[p] && matches(p) {
+				paths = append(paths, p)
+			}
+		}
+		return filesLoadedMsg(search.WalkRoots(paths))
+	}
+}
+
+// pseudoScopeErr rejects a query combining a pseudo-tag (@projects, @dirty)
+// with other terms: pseudo-tags are whole scopes, not tags paths carry.
+func pseudoScopeErr(q store.TagQuery) error {
+	for _, name := range q.Tags() {
+		if (name == projectsScope || name == dirtyScope) && q.String() != name {
+			return fmt.Errorf("@%s can't be combined with other tags", name)
+		}
+	}
+	return nil
+}
+
+// projectsScope is the pseudo-tag (@projects) that searches detected project roots.
+const projectsScope = "projects"
+
+// recordProjects stores the project roots found while walking root,
+// including root itself. Only roots not known yet are written.
+func recordProjects(db store.Store, root string, entries []search.Entry) {
+	roots := search.ProjectRoots(root, entries)
+	if search.IsProjectRoot(root) {
+		roots = append(roots, root)
+	}
+	known, err := db.GetProjects()
+	if err != nil {
+		return
+	}
+	var added []string
+	for _, r := range roots {
+		if !slices.Contains(known, r) {
+			added = append(added, r)
+		}
+	}
+	if len(added) > 0 {
+		_ = db.AddProjects(added)
+	}
+}
+
+// rankedProjects returns the known project roots that still exist, ranked by
+// the frecency of visits inside them. Vanished ones are skipped; see
+// sweepDeadProjects for forgetting them.
+func rankedProjects(db store.Store) []string {
+	projects, _ := db.GetProjects()
+	history, _ := db.GetHistory()
+	var live []string
+	for _, p := range projects {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		live = append(live, p)
+	}
+	return store.RankProjects(live, history, time.Now())
+}
+
+// loadProjectFiles loads the @projects scope: project roots, best ranked first.
+func loadProjectFiles(db store.Store) tea.Cmd {
+	return func() tea.Msg {
+		return filesLoadedMsg(statEntries(rankedProjects(db)))
 	}
 }
 
@@ -257,10 +452,10 @@ func performSearch(files []string, query string) tea.Cmd {
 	}
 }
 
-func buildSearchList(db *sql.DB, root string) []string {
+func buildSearchList(db store.Store, root string) []string {
 	// Build history + tags list
-	recentHistory, _ := store.GetRecentHistory(db, 100)
-	tagged, _ := store.GetAllTaggedPaths(db)
+	recentHistory, _ := db.GetRecentHistory(100)
+	tagged, _ := db.GetAllTaggedPaths()
 	pathSet := make(map[string]bool)
 	var historyFiles []string
 	for _, h := range recentHistory {
@@ -277,7 +472,7 @@ func buildSearchList(db *sql.DB, root string) []string {
 	}
 
 	currentFiles, _ := search.Walk(root)
-	return combineFiles(historyFiles, currentFiles)
+	return combineFiles(historyFiles, search.EntryPaths(currentFiles))
 }
 
 func defaultConfig() appConfig {
@@ -306,37 +501,50 @@ func defaultConfig() appConfig {
 		ExplorerCmd:   `xdg-open "{path}"`,
 		EditorCmd:     editorCmd,
 		CustomActions: "",
+		View:          "tree",
 	}
 }
 
-func loadConfig(db *sql.DB) appConfig {
+func loadConfig(db store.Store) appConfig {
 	cfg := defaultConfig()
-	if v, _ := store.GetSetting(db, "default_action"); v != "" {
+	if v, _ := db.GetSetting("default_action"); v != "" {
 		cfg.DefaultAction = v
 	}
-	if v, _ := store.GetSetting(db, "terminal_cmd"); v != "" {
+	if v, _ := db.GetSetting("terminal_cmd"); v != "" {
 		cfg.TerminalCmd = v
 	}
-	if v, _ := store.GetSetting(db, "explorer_cmd"); v != "" {
+	if v, _ := db.GetSetting("explorer_cmd"); v != "" {
 		cfg.ExplorerCmd = v
 	}
-	if v, _ := store.GetSetting(db, "editor_cmd"); v != "" {
+	if v, _ := db.GetSetting("editor_cmd"); v != "" {
 		cfg.EditorCmd = v
 	}
-	if v, _ := store.GetSetting(db, "custom_actions"); v != "" {
+	if v, _ := db.GetSetting("custom_actions"); v != "" {
 		cfg.CustomActions = v
 	}
+	if v, _ := db.GetSetting("view"); v != "" {
+		cfg.View = v
+	}
+	if v, _ := db.GetSetting("auto_tags"); v != "" {
+		cfg.AutoTags = v
+	}
 	return cfg
 }
 
-func saveConfig(db *sql.DB, cfg appConfig) {
-	_ = store.SetSetting(db, "default_action", cfg.DefaultAction)
-	_ = store.SetSetting(db, "terminal_cmd", cfg.TerminalCmd)
-	_ = store.SetSetting(db, "explorer_cmd", cfg.ExplorerCmd)
-	_ = store.SetSetting(db, "editor_cmd", cfg.EditorCmd)
-	_ = store.SetSetting(db, "custom_actions", cfg.CustomActions)
+func saveConfig(db store.Store, cfg appConfig) {
+	_ = db.SetSetting("default_action", cfg.DefaultAction)
+	_ = db.SetSetting("terminal_cmd", cfg.TerminalCmd)
+	_ = db.SetSetting("explorer_cmd", cfg.ExplorerCmd)
+	_ = db.SetSetting("editor_cmd", cfg.EditorCmd)
+	_ = db.SetSetting("custom_actions", cfg.CustomActions)
+	_ = db.SetSetting("view", cfg.View)
+	_ = db.SetSetting("auto_tags", cfg.AutoTags)
 }
 
+// startCommand starts the process of an action; tests replace it to see
+// what would run.
+var startCommand = (*exec.Cmd).Start
+
 func runCommandTemplate(cmdTemplate, path string) error {
 	cmdStr := strings.ReplaceAll(cmdTemplate, "{path}", path)
 	cmd := exec.Command("bash", "-lc", cmdStr)
@@ -348,7 +556,7 @@ func runCommandTemplate(cmdTemplate, path string) error {
 		cmd.Stdout = devNull
 		cmd.Stderr = devNull
 	}
-	return cmd.Start()
+	return startCommand(cmd)
 }
 
 func copyToClipboard(path string) error {
@@ -378,14 +586,15 @@ func pasteToFocusedInput(text string) {
 	}
 }
 
-func performAction(cfg appConfig, selectedPath string) {
+// performAction runs the configured action on selectedPath. Directory-based
+// actions (terminal, explorer) open the parent when isDir is false.
+func performAction(cfg appConfig, selectedPath string, isDir bool) {
 	absPath, err := filepath.Abs(selectedPath)
 	if err != nil {
 		absPath = selectedPath
 	}
 	path := absPath
-	info, err := os.Stat(selectedPath)
-	if err == nil && !info.IsDir() {
+	if !isDir {
 		path = filepath.Dir(absPath)
 	}
 
@@ -490,7 +699,373 @@ func resolveSelectedPath(selectedPath, baseDir string) string {
 	return filepath.Join(baseClean, cleaned)
 }
 
-func initialModel(db *sql.DB, cfg appConfig) model {
+// badgesMsg carries the frecency and tag lookups read by loadBadges.
+type badgesMsg struct {
+	frecency        map[string]float64
+	contextFrecency map[string]float64
+	pathTags        map[string][]string
+	tagDefs         map[string]store.TagDef
+	pins            []string
+	knownTags       []string
+	err             error // Reading the project tag file
+}
+
+// loadBadges reads the frecency and tag lookups shown on the results in the
+// background; setBadges applies them.
+func (m model) loadBadges() tea.Cmd {
+	db, context, dir, hasGit := m.db, m.context, m.currentDir, m.git != nil
+	return func() tea.Msg {
+		msg := badgesMsg{frecency: make(map[string]float64), contextFrecency: make(map[string]float64)}
+		history, _ := db.GetHistory()
+		now := time.Now()
+		for _, h := range history {
+			msg.frecency[h.Path] = h.Frecency(now)
+		}
+		visits, _ := db.GetContextHistory(context)
+		for _, h := range visits {
+			msg.contextFrecency[h.Path] = h.Frecency(now)
+		}
+		msg.pathTags, _ = db.GetTagsByPath()
+		msg.tagDefs, _ = db.GetTagDefs()
+		msg.pins, _ = db.GetPins()
+		if msg.pathTags == nil {
+			msg.pathTags = make(map[string][]string)
+		}
+		msg.knownTags, _ = db.GetAllTags()
+		if !slices.Contains(msg.knownTags, projectsScope) {
+			msg.knownTags = append(msg.knownTags, projectsScope)
+		}
+		if hasGit && !slices.Contains(msg.knownTags, dirtyScope) {
+			msg.knownTags = append(msg.knownTags, dirtyScope)
+		}
+		var projectTags map[string][]string
+		projectTags, msg.err = project.Tags(dir)
+		for tag, paths := range projectTags {
+			if !slices.Contains(msg.knownTags, tag) {
+				msg.knownTags = append(msg.knownTags, tag)
+			}
+			for _, p := range paths {
+				if !slices.Contains(msg.pathTags[p], tag) {
+					msg.pathTags[p] = append(msg.pathTags[p], tag)
+				}
+			}
+		}
+		sort.Strings(msg.knownTags)
+		return msg
+	}
+}
+
+// setBadges applies the lookups read by loadBadges.
+func (m *model) setBadges(msg badgesMsg) {
+	m.frecency = msg.frecency
+	m.contextFrecency = msg.contextFrecency
+	m.pathTags = msg.pathTags
+	m.tagDefs = msg.tagDefs
+	m.pins = msg.pins
+	m.knownTags = msg.knownTags
+	m.err = msg.err
+	if m.activeTag != "" {
+		m.markTaggedPaths()
+	}
+}
+
+// markTaggedPaths highlights the tagged paths themselves in a tag scope,
+// not everything inside them.
+func (m *model) markTaggedPaths() {
+	m.historyPaths = make(map[string]bool)
+	for _, path := range m.allFiles {
+		if len(m.pathTags[path]) > 0 {
+			m.historyPaths[path] = true
+		}
+	}
+}
+
+// tagStyles returns the badge and marker style of every defined tag.
+func (m model) tagStyles() map[string]ui.TagStyle {
+	styles := make(map[string]ui.TagStyle, len(m.tagDefs))
+	for name, def := range m.tagDefs {
+		styles[name] = ui.TagStyle{Color: def.Color, Icon: def.Icon}
+	}
+	return styles
+}
+
+// treeTags keys pathTags by the node paths (see ui.NodePath) of both the
+// absolute and the root-relative form of each path, as results use either.
+func (m model) treeTags() map[string][]string {
+	tags := make(map[string][]string, len(m.pathTags))
+	for abs, names := range m.pathTags {
+		tags[ui.NodePath(abs)] = names
+		if rel, err := filepath.Rel(m.currentDir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			tags[ui.NodePath(rel)] = names
+		}
+	}
+	return tags
+}
+
+// setTagQuery scopes the search to q (empty for local search). A single tag
+// with its own default action selects it while active; leaving restores the
+// previous one.
+func (m *model) setTagQuery(q store.TagQuery) {
+	if m.actionBeforeTag != "" {
+		// Keep an action the user picked with Tab while the tag was active
+		if m.config.DefaultAction == m.tagDefs[m.activeTag].Action {
+			m.config.DefaultAction = m.actionBeforeTag
+		}
+		m.actionBeforeTag = ""
+	}
+	m.tagQuery = q
+	m.activeTag = q.String()
+	m.tagQueryErr = pseudoScopeErr(q)
+	m.unknownTags = nil
+	for _, name := range q.Tags() {
+		if !slices.Contains(m.knownTags, name) {
+			m.unknownTags = append(m.unknownTags, name)
+		}
+	}
+	if m.activeTag == "" {
+		return
+	}
+	if action := m.tagDefs[m.activeTag].Action; action != "" && action != m.config.DefaultAction {
+		for _, a := range buildActions(m.config) {
+			if a == action {
+				m.actionBeforeTag = m.config.DefaultAction
+				m.config.DefaultAction = action
+				break
+			}
+		}
+	}
+}
+
+// selectedResultIsDir reports whether the selection in the active view is a directory.
+func (m model) selectedResultIsDir() bool {
+	if m.config.View == "list" {
+		return m.entries[m.list.SelectedPath()].IsDir()
+	}
+	return m.tree.SelectedIsDir()
+}
+
+// selectedResultPath returns the path selected in whichever view is active.
+func (m model) selectedResultPath() string {
+	if m.config.View == "list" {
+		return m.list.SelectedPath()
+	}
+	return m.tree.SelectedPath()
+}
+
+// openSelected records the selection in history, runs the default action on it and quits.
+func (m model) openSelected() (tea.Model, tea.Cmd) {
+	// Handle Selection form Tree
+	selectedPath := m.selectedResultPath()
+	if selectedPath == "" {
+		return m, nil
+	}
+
+	resolvedPath := resolveSelectedPath(selectedPath, m.currentDir)
+	if absPath, err := filepath.Abs(resolvedPath); err == nil {
+		resolvedPath = absPath
+	}
+	// Update History and remember the pick for the query that found it
+	_ = recordVisit(m.db, resolvedPath, m.context)
+	if _, ok := aliasQuery(m.input.Value()); !ok {
+		_ = m.db.RecordQueryPick(m.searchQuery(), resolvedPath)
+	}
+	// Mark as history (use tree path for highlighting)
+	m.historyPaths[selectedPath] = true
+	m.selectedPath = resolvedPath
+	performAction(m.config, resolvedPath, m.selectedResultIsDir())
+	return m, tea.Quit
+}
+
+// openPath runs the selected action on an absolute path from outside the
+// results (a pin, a history entry) and quits, like Enter on a result.
+func (m model) openPath(path string) (tea.Model, tea.Cmd) {
+	_ = recordVisit(m.db, path, m.context)
+	info, err := os.Stat(path)
+	m.selectedPath = path
+	performAction(m.config, path, err == nil && info.IsDir())
+	return m, tea.Quit
+}
+
+// inputChanged reacts to a new search input value: activating or leaving an
+// @tag scope, completing a partially typed tag, or searching.
+func (m model) inputChanged(newValue string) (model, tea.Cmd) {
+	var cmds []tea.Cmd
+	m.tagCompletions = nil
+	if _, ok := aliasQuery(newValue); ok {
+		// Resolved from the aliases when the (empty) results arrive
+		return m, performSearch(nil, "")
+	}
+	// Parsing Logic for Tags: complete terms are followed by a space
+	q, rest := store.ParseTagQuery(newValue)
+	if pending, ok := pendingTagTerm(rest); ok {
+		// Typing a tag: offer completions instead of searching for "@..."
+		m.tagCompletions = search.CompleteTags(m.knownTags, pending)
+		m.tagCompletion = 0
+	} else if !q.IsEmpty() {
+		if q.String() != m.activeTag {
+			m.setTagQuery(q)
+			// Load files for the tag query
+			cmds = append(cmds, loadTagFiles(m.db, m.git, m.currentDir, m.tagQuery))
+			// Return to wait for filesLoadedMsg
+			return m, tea.Batch(cmds...)
+		}
+
+		// Tag active, search with rest inside the tag scope only
+		cmds = append(cmds, performSearch(m.allFiles, rest))
+	} else {
+		// Standard local search
+		if m.activeTag != "" {
+			// Backspaced out of tag?
+			m.setTagQuery(store.TagQuery{})
+			cmds = append(cmds, loadFiles(m.db, m.currentDir))
+		} else {
+			// If user starts typing and current directory not loaded yet, load it
+			if !m.currentDirLoaded && newValue != "" {
+				m.currentDirLoaded = true
+				cmds = append(cmds, loadFiles(m.db, m.currentDir))
+			} else {
+				// Search in combined files (history + current dir if loaded)
+				searchFiles := m.allFiles
+				if m.currentDirLoaded && len(m.currentDirFiles) > 0 {
+					// Recombine to ensure we have latest
+					searchFiles = combineFiles(m.historyFiles, m.currentDirFiles)
+				}
+				cmds = append(cmds, performSearch(searchFiles, newValue))
+			}
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// pendingTagTerm reports whether rest is a tag term still being typed
+// (`@wo`, `@work,@pe`, `-@ar`) and returns the partial tag name after its last '@'.
+func pendingTagTerm(rest string) (string, bool) {
+	if !store.IsTagTerm(rest) || strings.Contains(rest, " ") {
+		return "", false
+	}
+	return rest[strings.LastIndex(rest, "@")+1:], true
+}
+
+// searchQuery returns the input with the active @tag terms stripped.
+func (m model) searchQuery() string {
+	if m.activeTag == "" {
+		return m.input.Value()
+	}
+	_, rest := store.ParseTagQuery(m.input.Value())
+	return rest
+}
+
+// forgetSelected removes the selected path from history and drops it from
+// the history list unless it is still tagged.
+func (m model) forgetSelected() (tea.Model, tea.Cmd) {
+	selectedPath := m.selectedResultPath()
+	if selectedPath == "" {
+		return m, nil
+	}
+	resolvedPath := resolveSelectedPath(selectedPath, m.currentDir)
+	if absPath, err := filepath.Abs(resolvedPath); err == nil {
+		resolvedPath = absPath
+	}
+	_, _ = m.db.RemoveHistory(resolvedPath)
+	delete(m.historyPaths, selectedPath)
+	delete(m.frecency, resolvedPath)
+	if len(m.pathTags[resolvedPath]) > 0 {
+		return m, nil
+	}
+
+	var kept []string
+	for _, p := range m.historyFiles {
+		if p != selectedPath {
+			kept = append(kept, p)
+		}
+	}
+	m.setHistoryFiles(kept)
+	return m, performSearch(m.allFiles, m.searchQuery())
+}
+
+// setHistoryFiles replaces the history part of the results, recombining it
+// with the current directory unless a tag scope is active.
+func (m *model) setHistoryFiles(files []string) {
+	m.historyFiles = files
+	if m.activeTag == "" {
+		if m.currentDirLoaded {
+			m.allFiles = combineFiles(m.historyFiles, m.currentDirFiles)
+		} else {
+			m.allFiles = m.historyFiles
+		}
+	}
+}
+
+// changeDir re-roots the search at dir, pushing the current root onto the back stack.
+func (m model) changeDir(dir string) (model, tea.Cmd) {
+	if dir == "" || dir == m.currentDir {
+		return m, nil
+	}
+	m.dirBack = append(m.dirBack, m.currentDir)
+	m.dirForward = nil
+	return m.rootAt(dir)
+}
+
+// rootAt points the search at dir and reloads its files without touching the stacks.
+func (m model) rootAt(dir string) (model, tea.Cmd) {
+	m.currentDir = dir
+	m.input.SetValue("")
+	m.setTagQuery(store.TagQuery{})
+	m.currentDirLoaded = false
+	return m, loadFiles(m.db, m.currentDir)
+}
+
+// doubleClickInterval is the maximum gap between two clicks on the same result
+// for them to count as a double-click.
+const doubleClickInterval = 400 * time.Millisecond
+
+// handleMouse routes mouse events in browse mode. The screen is laid out as
+// header, results (tree.Height rows), action tabs and shortcuts, top to bottom.
+func (m model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	isClick := msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+
+	if isClick && msg.Y == 0 {
+		if dir, ok := ui.BreadcrumbAt(m.currentDir, m.breadcrumbWidth(), msg.X); ok {
+			return m.changeDir(dir)
+		}
+		return m, nil
+	}
+
+	// Translate to coordinates relative to the results view
+	local := msg
+	local.Y -= lipgloss.Height(m.headerView())
+	resultsHeight := m.tree.Height
+
+	if local.Y >= 0 && local.Y < resultsHeight {
+		var hit bool
+		if m.config.View == "list" {
+			hit = m.list.ItemAt(local.Y) >= 0
+			m.list, _ = m.list.Update(local)
+		} else {
+			hit = m.tree.NodeAt(local.X, local.Y) != nil
+			m.tree, _ = m.tree.Update(local)
+		}
+		if isClick && hit {
+			path := m.selectedResultPath()
+			now := time.Now()
+			if path == m.lastClickPath && now.Sub(m.lastClickTime) < doubleClickInterval {
+				return m.openSelected()
+			}
+			m.lastClickPath = path
+			m.lastClickTime = now
+		}
+		return m, nil
+	}
+
+	if isClick && local.Y == resultsHeight {
+		if action, ok := m.actionTabAt(msg.X); ok {
+			m.config.DefaultAction = action
+		}
+	}
+	return m, nil
+}
+
+func initialModel(db store.Store, cfg appConfig) model {
 	ti := textinput.New()
 	ti.Placeholder = "Search... (use @tag for scopes)"
 	ti.Focus()
@@ -502,7 +1077,7 @@ func initialModel(db *sql.DB, cfg appConfig) model {
 	wd, _ := os.Getwd()
 
 	// Init empty tree
-	tm := ui.NewTreeModel([]string{}, 80, 20, make(map[string]bool))
+	tm := ui.NewTreeModel([]string{}, 80, 20, make(map[string]bool), nil)
 	configInput := textinput.New()
 	configInput.Placeholder = "Value"
 	configInput.CharLimit = 512
@@ -514,12 +1089,22 @@ func initialModel(db *sql.DB, cfg appConfig) model {
 	tagInput.Width = 30
 	tagInput.Blur()
 
+	var git *vcs.Cache
+	if vcs.Available() {
+		git = vcs.NewCache()
+	}
+
 	return model{
 		db:           db,
+		git:          git,
 		input:        ti,
 		tree:         tm,
 		currentDir:   wd,
+		context:      visitContext(wd),
 		historyPaths: make(map[string]bool),
+		entries:      make(map[string]search.Entry),
+		frecency:     make(map[string]float64),
+		pathTags:     make(map[string][]string),
 		isInitialLoad: true,
 		currentDirLoaded: false,
 		mode:         modeBrowse,
@@ -539,7 +1124,7 @@ func initialModel(db *sql.DB, cfg appConfig) model {
 
 func (m model) Init() tea.Cmd {
 	// On initial load, show recent history + tagged paths only
-	return tea.Batch(textinput.Blink, loadInitialFiles(m.db))
+	return tea.Batch(textinput.Blink, loadInitialFiles(m.db, m.context))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -548,14 +1133,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case filesLoadedMsg:
+		cmds = append(cmds, m.loadBadges())
+		for _, e := range msg {
+			m.entries[e.Path] = e
+		}
+		paths := search.EntryPaths(msg)
 		// Determine if this is history/tags load or current directory load
 		if m.isInitialLoad {
-			// Initial load: history + tags
-			m.historyFiles = msg
+			// Initial load: history + tags. Housekeeping waits until
+			// they are shown.
+			m.historyFiles = paths
 			m.isInitialLoad = false
+			cmds = append(cmds, sweepCmd(m.db))
 			// Mark all paths as history
 			m.historyPaths = make(map[string]bool)
-			for _, path := range msg {
+			for _, path := range paths {
 				m.historyPaths[path] = true
 			}
 			// Combine with current directory files if already loaded
@@ -565,23 +1157,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.allFiles = m.historyFiles
 			}
 		} else if m.activeTag != "" {
-			// Tag load
-			m.allFiles = msg
-			m.historyPaths = make(map[string]bool)
-			for _, path := range msg {
-				m.historyPaths[path] = true
-			}
+			// Tag load: the tagged paths and everything inside them
+			m.allFiles = paths
+			m.markTaggedPaths()
 		} else {
 			// Current directory load
-			m.currentDirFiles = msg
+			m.currentDirFiles = paths
 			m.currentDirLoaded = true
 			// Update historyPaths for paths actually in history
-			history, _ := store.GetHistory(m.db)
+			history, _ := m.db.GetHistory()
 			historySet := make(map[string]bool)
 			for _, h := range history {
 				historySet[h.Path] = true
 			}
-			for _, path := range msg {
+			for _, path := range paths {
 				if historySet[path] {
 					m.historyPaths[path] = true
 				}
@@ -590,22 +1179,35 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.allFiles = combineFiles(m.historyFiles, m.currentDirFiles)
 		}
 		// Trigger search
-		parsedQuery := m.input.Value()
-		if m.activeTag != "" {
-			parsedQuery = strings.TrimPrefix(parsedQuery, "@"+m.activeTag)
-			parsedQuery = strings.TrimPrefix(parsedQuery, " ")
-		}
-		cmds = append(cmds, performSearch(m.allFiles, parsedQuery))
+		cmds = append(cmds, performSearch(m.allFiles, m.searchQuery()))
 
 	case searchDoneMsg:
+		// ":name" resolves aliases exactly, whatever search produced this
+		if name, ok := aliasQuery(m.input.Value()); ok {
+			msg = searchDoneMsg(m.aliasResults(name))
+		}
 		var paths []string
 		for _, res := range msg {
 			paths = append(paths, res.Path)
 		}
+		// Boost dirty and recently modified results with the git status
+		// cached so far; stale repositories are refreshed in the background.
+		// Paths picked for this query before come first.
+		gitStates, staleRepos := m.gitStates(paths)
+		if query := m.searchQuery(); query != "" && !strings.HasPrefix(query, aliasPrefix) {
+			search.Boost(msg, m.rankBonus(gitStates, time.Now()))
+			promotePicks(m.db, msg, query, m.currentDir)
+			for i, res := range msg {
+				paths[i] = res.Path
+			}
+		}
+		if len(staleRepos) > 0 {
+			cmds = append(cmds, refreshGit(m.git, staleRepos))
+		}
 		// Rebuild tree with new paths
 		// Use window dimensions if available, otherwise use existing tree dimensions
 		treeWidth := m.width
-		treeHeight := m.height - 3
+		treeHeight := m.height - chromeHeight
 		if treeWidth == 0 || treeHeight <= 0 {
 			// Window size not set yet, use existing tree dimensions or defaults
 			if m.tree.Width > 0 {
@@ -619,8 +1221,42 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				treeHeight = 20 // Default height
 			}
 		}
-		// Pass history paths to tree for visual distinction
-		m.tree = ui.NewTreeModel(paths, treeWidth, treeHeight, m.historyPaths)
+		// Pass history paths to tree for visual distinction.
+		// SetPaths reuses the previous nodes and keeps the user's selection.
+		m.tree.Width = treeWidth
+		m.tree.Height = treeHeight
+		m.tree.SetPaths(paths, m.historyPaths, m.entries)
+		m.tree.PathTags = m.treeTags()
+		m.tree.TagStyles = m.tagStyles()
+		m.tree.GitStatus = make(map[string]vcs.State, len(gitStates))
+		for p, s := range gitStates {
+			m.tree.GitStatus[ui.NodePath(p)] = s
+		}
+
+		items := make([]ui.ListItem, 0, len(msg))
+		for _, res := range msg {
+			absPath := resolveSelectedPath(res.Path, m.currentDir)
+			items = append(items, ui.ListItem{
+				Path:      res.Path,
+				Score:     res.Score,
+				Frecency:  m.frecency[absPath],
+				Tags:      m.pathTags[absPath],
+				Matches:   res.Matches,
+				IsHistory: m.historyPaths[res.Path],
+				Entry:     m.entries[res.Path],
+				Git:       gitStates[res.Path],
+			})
+		}
+		m.list = ui.NewListModel(items, treeWidth, treeHeight, listLimit)
+		m.list.TagStyles = m.tree.TagStyles
+
+	case gitStatusMsg:
+		cmds = append(cmds, performSearch(m.allFiles, m.searchQuery()))
+
+	case badgesMsg:
+		// Rank and mark the results again with the new lookups
+		m.setBadges(msg)
+		cmds = append(cmds, performSearch(m.allFiles, m.searchQuery()))
 
 	case tea.KeyMsg:
 		if m.mode == modeConfig {
@@ -680,6 +1316,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						case 4:
 							m.config.CustomActions = val
 							ensureDefaultAction(&m.config)
+						case 5:
+							m.config.AutoTags = val
 						}
 						saveConfig(m.db, m.config)
 						m.configInput.Blur()
@@ -706,7 +1344,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.configField--
 				}
 			case "down":
-				if m.configField < 4 {
+				if m.configField < 5 {
 					m.configField++
 				}
 			case "left", "right", " ":
@@ -797,6 +1435,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.configInput.SetValue(m.config.ExplorerCmd)
 					case 3:
 						m.configInput.SetValue(m.config.EditorCmd)
+					case 5:
+						m.configInput.SetValue(m.config.AutoTags)
 					}
 					m.configInput.Focus()
 					m.configInput.CursorEnd()
@@ -805,18 +1445,71 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(cmds...)
 		}
 
+		if m.mode == modeTagManager {
+			return m.updateTagManager(msg)
+		}
+
+		if m.mode == modePins {
+			return m.updatePins(msg)
+		}
+
+		if m.mode == modeHistory {
+			return m.updateHistory(msg)
+		}
+
 		if m.mode == modeTags {
+			if m.tagDefStep > 0 {
+				switch msg.String() {
+				case "esc":
+					m.tagDefStep = 0
+					m.tagInput.Blur()
+					m.tagInput.SetValue("")
+				case "enter":
+					val := strings.TrimSpace(m.tagInput.Value())
+					switch m.tagDefStep {
+					case 1:
+						m.tagDef.Description = val
+					case 2:
+						m.tagDef.Color = val
+					case 3:
+						m.tagDef.Icon = val
+					case 4:
+						m.tagDef.Action = val
+					}
+					if m.tagDefStep < 4 {
+						m.tagDefStep++
+						m.tagInput.SetValue(tagDefField(m.tagDef, m.tagDefStep))
+						m.tagInput.CursorEnd()
+					} else {
+						_ = m.db.SetTagDef(m.tagDef)
+						m.tagDefs, _ = m.db.GetTagDefs()
+						m.tagDefStep = 0
+						m.tagInput.Blur()
+						m.tagInput.SetValue("")
+					}
+				default:
+					m.tagInput, cmd = m.tagInput.Update(msg)
+					cmds = append(cmds, cmd)
+				}
+				return m, tea.Batch(cmds...)
+			}
+
 			if m.tagEditing {
 				switch msg.String() {
 				case "esc":
 					m.tagEditing = false
+					m.tagErr = nil
 					m.tagInput.Blur()
 					m.tagInput.SetValue("")
 				case "enter":
-					tag := strings.TrimSpace(m.tagInput.Value())
-					if tag != "" {
-						_ = store.AddPathToTag(m.db, tag, m.tagPath)
-						m.tagList, _ = store.GetTagsForPath(m.db, m.tagPath)
+					tag := strings.TrimPrefix(strings.TrimSpace(m.tagInput.Value()), "@")
+					if tag == "" {
+						m.tagErr = nil
+					} else if m.tagErr = store.ValidateTagName(tag); m.tagErr != nil {
+						return m, nil
+					} else {
+						_ = m.db.AddPathToTag(tag, m.tagPath)
+						m.tagList, _ = m.db.GetTagsForPath(m.tagPath)
 					}
 					m.tagEditing = false
 					m.tagInput.Blur()
@@ -844,13 +1537,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case "a":
 				m.tagEditing = true
+				m.tagErr = nil
 				m.tagInput.SetValue("")
 				m.tagInput.Focus()
 				m.tagInput.CursorEnd()
+			case "e":
+				if len(m.tagList) > 0 && m.tagSelected >= 0 && m.tagSelected < len(m.tagList) {
+					m.tagDef = m.tagDefs[m.tagList[m.tagSelected]]
+					m.tagDef.Name = m.tagList[m.tagSelected]
+					m.tagDefStep = 1
+					m.tagInput.SetValue(m.tagDef.Description)
+					m.tagInput.Focus()
+					m.tagInput.CursorEnd()
+				}
 			case "d":
 				if len(m.tagList) > 0 && m.tagSelected >= 0 && m.tagSelected < len(m.tagList) {
-					_ = store.RemovePathFromTag(m.db, m.tagList[m.tagSelected], m.tagPath)
-					m.tagList, _ = store.GetTagsForPath(m.db, m.tagPath)
+					_ = m.db.RemovePathFromTag(m.tagList[m.tagSelected], m.tagPath)
+					m.tagList, _ = m.db.GetTagsForPath(m.tagPath)
 					if m.tagSelected >= len(m.tagList) {
 						m.tagSelected = len(m.tagList) - 1
 					}
@@ -859,18 +1562,85 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(cmds...)
 		}
 
+		// Choosing a breadcrumb: Left/Right move, Enter re-roots there
+		if m.crumbFocus {
+			crumbs := ui.Crumbs(m.currentDir)
+			switch msg.String() {
+			case "left":
+				m.crumbIndex = max(m.crumbIndex-1, 0)
+				return m, nil
+			case "right":
+				m.crumbIndex = min(m.crumbIndex+1, len(crumbs)-1)
+				return m, nil
+			case "enter":
+				m.crumbFocus = false
+				return m.changeDir(crumbs[m.crumbIndex].Path)
+			case "esc", "ctrl+b":
+				m.crumbFocus = false
+				return m, nil
+			}
+			// Any other key goes back to the search as usual
+			m.crumbFocus = false
+		}
+
+		if len(m.tagCompletions) > 0 {
+			switch msg.String() {
+			case "tab", "enter":
+				// Replace the partial tag with the highlighted completion
+				value := m.input.Value()
+				value = value[:strings.LastIndex(value, "@")+1]
+				m.input.SetValue(value + m.tagCompletions[m.tagCompletion] + " ")
+				m.input.CursorEnd()
+				return m.inputChanged(m.input.Value())
+			case "up", "shift+tab":
+				if m.tagCompletion > 0 {
+					m.tagCompletion--
+				}
+				return m, nil
+			case "down":
+				if m.tagCompletion < len(m.tagCompletions)-1 {
+					m.tagCompletion++
+				}
+				return m, nil
+			case "esc":
+				m.tagCompletions = nil
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "ctrl+o":
 			m.mode = modeConfig
 			return m, nil
+		case "ctrl+g":
+			return m.openTagManager(), nil
+		case "ctrl+r":
+			return m.openHistory(), nil
+		case "ctrl+p":
+			candidate := ""
+			if selectedPath := m.selectedResultPath(); selectedPath != "" {
+				candidate = resolveSelectedPath(selectedPath, m.currentDir)
+				if absPath, err := filepath.Abs(candidate); err == nil {
+					candidate = absPath
+				}
+			}
+			return m.openPins(candidate), nil
+		case "ctrl+l":
+			// Toggle between the column tree and the flat ranked list
+			if m.config.View == "list" {
+				m.config.View = "tree"
+			} else {
+				m.config.View = "list"
+			}
+			saveConfig(m.db, m.config)
+			return m, nil
 		case "ctrl+t":
-			selectedPath := m.tree.SelectedPath()
+			selectedPath := m.selectedResultPath()
 			if selectedPath == "" {
 				selectedPath = m.currentDir
-			}
-			if info, err := os.Stat(selectedPath); err == nil && !info.IsDir() {
+			} else if !m.selectedResultIsDir() {
 				selectedPath = filepath.Dir(selectedPath)
 			}
 			selectedPath = resolveSelectedPath(selectedPath, m.currentDir)
@@ -878,46 +1648,63 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				selectedPath = absPath
 			}
 			m.tagPath = selectedPath
-			m.tagList, _ = store.GetTagsForPath(m.db, m.tagPath)
+			m.tagList, _ = m.db.GetTagsForPath(m.tagPath)
 			m.tagSelected = 0
 			m.tagEditing = false
+			m.tagDefStep = 0
 			m.tagInput.SetValue("")
 			m.mode = modeTags
 			return m, nil
 		case "ctrl+d":
 			// Drill down into directory without triggering action
-			selectedPath := m.tree.SelectedPath()
+			selectedPath := m.selectedResultPath()
 			if selectedPath == "" {
 				return m, nil
 			}
-			if info, err := os.Stat(resolveSelectedPath(selectedPath, m.currentDir)); err == nil && info.IsDir() {
-				_ = store.UpdateFrecency(m.db, resolveSelectedPath(selectedPath, m.currentDir))
+			if m.selectedResultIsDir() {
+				_ = recordVisit(m.db, resolveSelectedPath(selectedPath, m.currentDir), m.context)
 				m.historyPaths[selectedPath] = true
-				m.currentDir = resolveSelectedPath(selectedPath, m.currentDir)
-				m.input.SetValue("")
-				m.activeTag = ""
-				m.currentDirLoaded = false
-				cmds = append(cmds, loadFiles(m.db, m.currentDir))
+				m, cmd = m.changeDir(resolveSelectedPath(selectedPath, m.currentDir))
+				cmds = append(cmds, cmd)
 			}
 			return m, tea.Batch(cmds...)
-		case "enter":
-			// Handle Selection form Tree
-			selectedPath := m.tree.SelectedPath()
-			if selectedPath == "" {
+		case "ctrl+x":
+			// Forget the selected path from history
+			return m.forgetSelected()
+		case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+			// Jump to a pin, leaving plain digits to the search
+			if path, ok := m.pinPath(strings.TrimPrefix(msg.String(), "alt+")); ok {
+				return m.openPath(path)
+			}
+			return m, nil
+		case "ctrl+b":
+			// Choose a breadcrumb segment, starting at the parent
+			if crumbs := ui.Crumbs(m.currentDir); len(crumbs) > 0 {
+				m.crumbFocus = true
+				m.crumbIndex = max(len(crumbs)-2, 0)
+			}
+			return m, nil
+		case "alt+up":
+			// Re-root one level up
+			return m.changeDir(filepath.Dir(m.currentDir))
+		case "alt+left":
+			if len(m.dirBack) == 0 {
 				return m, nil
 			}
-
-			resolvedPath := resolveSelectedPath(selectedPath, m.currentDir)
-			if absPath, err := filepath.Abs(resolvedPath); err == nil {
-				resolvedPath = absPath
+			prev := m.dirBack[len(m.dirBack)-1]
+			m.dirBack = m.dirBack[:len(m.dirBack)-1]
+			m.dirForward = append(m.dirForward, m.currentDir)
+			return m.rootAt(prev)
+		case "alt+right":
+			if len(m.dirForward) == 0 {
+				return m, nil
 			}
-			// Update History
-			_ = store.UpdateFrecency(m.db, resolvedPath)
-			// Mark as history (use tree path for highlighting)
-			m.historyPaths[selectedPath] = true
-			m.selectedPath = resolvedPath
-			performAction(m.config, resolvedPath)
-			return m, tea.Quit
+			next := m.dirForward[len(m.dirForward)-1]
+			m.dirForward = m.dirForward[:len(m.dirForward)-1]
+			m.dirBack = append(m.dirBack, m.currentDir)
+			return m.rootAt(next)
+		case "enter":
+			return m.openSelected()
 
 		case "tab":
 			actions := buildActions(m.config)
@@ -942,8 +1729,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			idx = (idx + len(actions) - 1) % len(actions)
 			m.config.DefaultAction = actions[idx]
 
-		case "up", "down", "left", "right":
-			// Pass to tree
+		case "up", "down", "left", "right", "pgup", "pgdown":
+			// Pass to the active view
+			if m.config.View == "list" {
+				var listCmd tea.Cmd
+				m.list, listCmd = m.list.Update(msg)
+				cmds = append(cmds, listCmd)
+				break
+			}
 			var treeCmd tea.Cmd
 			m.tree, treeCmd = m.tree.Update(msg)
 			cmds = append(cmds, treeCmd)
@@ -952,80 +1745,30 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.input, cmd = m.input.Update(msg)
 			cmds = append(cmds, cmd)
 
-			newValue := m.input.Value()
-			if newValue != oldValue {
-				// Parsing Logic for Tags
-				if strings.HasPrefix(newValue, "@") && strings.Contains(newValue, " ") {
-					parts := strings.SplitN(newValue, " ", 2)
-					potentialTag := strings.TrimPrefix(parts[0], "@")
-
-					if potentialTag != m.activeTag {
-						m.activeTag = potentialTag
-						// Load files for tag
-						cmds = append(cmds, loadTagFiles(m.db, m.activeTag))
-						// Return to wait for filesLoadedMsg
-						return m, tea.Batch(cmds...)
-					}
-
-					// Tag active, search with rest
-					query := ""
-					if len(parts) > 1 {
-						query = parts[1]
-					}
-					// Use combined files if current dir is loaded
-					searchFiles := m.allFiles
-					if m.currentDirLoaded && len(m.currentDirFiles) > 0 {
-						searchFiles = combineFiles(m.historyFiles, m.currentDirFiles)
-					}
-					cmds = append(cmds, performSearch(searchFiles, query))
-				} else if strings.HasPrefix(newValue, "@") {
-					// Typing tag... search in current files
-					searchFiles := m.allFiles
-					if m.currentDirLoaded && len(m.currentDirFiles) > 0 {
-						searchFiles = combineFiles(m.historyFiles, m.currentDirFiles)
-					}
-					cmds = append(cmds, performSearch(searchFiles, newValue))
-				} else {
-					// Standard local search
-					if m.activeTag != "" {
-						// Backspaced out of tag?
-						m.activeTag = ""
-						cmds = append(cmds, loadFiles(m.db, m.currentDir))
-					} else {
-						// If user starts typing and current directory not loaded yet, load it
-						if !m.currentDirLoaded && newValue != "" {
-							m.currentDirLoaded = true
-							cmds = append(cmds, loadFiles(m.db, m.currentDir))
-						} else {
-							// Search in combined files (history + current dir if loaded)
-							searchFiles := m.allFiles
-							if m.currentDirLoaded && len(m.currentDirFiles) > 0 {
-								// Recombine to ensure we have latest
-								searchFiles = combineFiles(m.historyFiles, m.currentDirFiles)
-							}
-							cmds = append(cmds, performSearch(searchFiles, newValue))
-						}
-					}
-				}
+			if newValue := m.input.Value(); newValue != oldValue {
+				var inputCmd tea.Cmd
+				m, inputCmd = m.inputChanged(newValue)
+				cmds = append(cmds, inputCmd)
 			}
 		}
 
+	case tea.MouseMsg:
+		if m.mode == modeBrowse {
+			return m.handleMouse(msg)
+		}
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		inputHeight := 3
-		listHeight := msg.Height - inputHeight
+		listHeight := msg.Height - chromeHeight
 		if listHeight > 0 {
 			m.tree.Width = msg.Width
 			m.tree.Height = listHeight
+			m.list.Width = msg.Width
+			m.list.Height = listHeight
 			// If we have files loaded, rebuild tree with new dimensions
 			if len(m.allFiles) > 0 {
-				parsedQuery := m.input.Value()
-				if m.activeTag != "" {
-					parsedQuery = strings.TrimPrefix(parsedQuery, "@"+m.activeTag)
-					parsedQuery = strings.TrimPrefix(parsedQuery, " ")
-				}
-				cmds = append(cmds, performSearch(m.allFiles, parsedQuery))
+				cmds = append(cmds, performSearch(m.allFiles, m.searchQuery()))
 			}
 		}
 		m.input.Width = msg.Width
@@ -1041,26 +1784,172 @@ func (m model) View() string {
 	if m.mode == modeTags {
 		return m.tagsView()
 	}
-
-	header := m.input.View()
-	if m.activeTag != "" {
-		tagStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
-		header = fmt.Sprintf("%s %s", tagStyle.Render("[@"+m.activeTag+"]"), m.input.View())
+	if m.mode == modeTagManager {
+		return m.tagManagerView()
+	}
+	if m.mode == modePins {
+		return m.pinsView()
+	}
+	if m.mode == modeHistory {
+		return m.historyView()
 	}
 
-	shortcuts := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(
-		"Ctrl+O: config  Ctrl+T: tags  Ctrl+D: drill  Tab/Shift+Tab: action  Enter: open  Ctrl+C: quit",
-	)
+	header := m.headerView()
+
+	shortcuts := m.shortcutsView()
+
+	results := m.tree.View()
+	if m.config.View == "list" {
+		results = m.list.View()
+	}
+	if len(m.tagCompletions) > 0 {
+		results = m.tagCompletionsView()
+	} else if m.tagQueryErr != nil {
+		results = m.padLines([]string{lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Bold(true).Render(m.tagQueryErr.Error())})
+	} else if len(m.unknownTags) > 0 && len(m.allFiles) == 0 {
+		results = m.unknownTagView()
+	}
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		header,
-		m.tree.View(),
+		results,
 		m.actionTabsView(),
-		shortcuts,
+		m.statusView(shortcuts),
 	)
 }
 
+// shortcutHints are the key hints of the shortcuts line, most useful first.
+// The README lists every key.
+var shortcutHints = []string{
+	"Enter: open", "Tab/Shift+Tab: action", "Ctrl+O: config", "Ctrl+T: tags", "Ctrl+G: all tags",
+	"Ctrl+P: pins", "Ctrl+R: history", "Ctrl+L: list/tree", "Alt+Up: parent", "Ctrl+B: breadcrumb",
+	"Alt+Left/Right: back/fwd", "Ctrl+D: drill", "Ctrl+X: forget", "Alt+1-9: open pin", "Ctrl+C: quit",
+}
+
+// shortcutsView renders the key hints for the current mode on one line,
+// keeping as many as fit the terminal width.
+func (m model) shortcutsView() string {
+	hints := shortcutHints
+	if m.crumbFocus {
+		hints = []string{"Left/Right: choose", "Enter: search there", "Esc: back to the search"}
+	}
+	line := ""
+	for _, h := range hints {
+		next := h
+		if line != "" {
+			next = line + "  " + h
+		}
+		if m.width > 0 && lipgloss.Width(next) > m.width {
+			break
+		}
+		line = next
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(line)
+}
+
+// statusView renders the last error, if any, in place of the shortcuts.
+func (m model) statusView(shortcuts string) string {
+	if m.err == nil {
+		return shortcuts
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Render("Error: " + m.err.Error())
+}
+
+// resultsHeight is the number of rows available to the results view.
+func (m model) resultsHeight() int {
+	if m.tree.Height > 0 {
+		return m.tree.Height
+	}
+	return 20
+}
+
+// padLines pads lines with blanks to the results height so the chrome below stays put.
+func (m model) padLines(lines []string) string {
+	for len(lines) < m.resultsHeight() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines[:m.resultsHeight()], "\n")
+}
+
+// tagCompletionsView renders the dropdown of tags matching the typed @prefix.
+func (m model) tagCompletionsView() string {
+	styles := m.tagStyles()
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	lines := []string{dim.Render("Tab/Enter: complete  Up/Down: choose  Esc: dismiss")}
+	for i, t := range m.tagCompletions {
+		prefix := "  "
+		if i == m.tagCompletion {
+			prefix = "> "
+		}
+		line := prefix + ui.TagBadge(t, styles[t])
+		if desc := m.tagDefs[t].Description; desc != "" {
+			line += "  " + dim.Render(desc)
+		}
+		lines = append(lines, line)
+	}
+	return m.padLines(lines)
+}
+
+// unknownTagView replaces the empty results of a tag query naming undefined tags.
+func (m model) unknownTagView() string {
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Bold(true)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	label := "Unknown tag @"
+	if len(m.unknownTags) > 1 {
+		label = "Unknown tags @"
+	}
+	lines := []string{warn.Render(label + strings.Join(m.unknownTags, " @"))}
+	if len(m.knownTags) > 0 {
+		lines = append(lines, dim.Render("Known tags: @"+strings.Join(m.knownTags, " @")))
+	} else {
+		lines = append(lines, dim.Render("No tags yet: add one with Ctrl+T or `navi --add <tag>`"))
+	}
+	return m.padLines(lines)
+}
+
+// chromeHeight is the number of rows around the results view:
+// breadcrumb, search input, action tabs and shortcuts.
+const chromeHeight = 4
+
+// headerView renders the breadcrumb of the current root above the search input.
+func (m model) headerView() string {
+	header := m.input.View()
+	if m.activeTag != "" {
+		def := m.tagDefs[m.activeTag]
+		style := ui.TagStyle{Color: def.Color, Icon: def.Icon}
+		if style.Color == "" {
+			style.Color = "205"
+		}
+		header = fmt.Sprintf("%s %s", lipgloss.NewStyle().Bold(true).Render(ui.TagBadge(m.activeTag, style)), m.input.View())
+	}
+	selected := -1
+	if m.crumbFocus {
+		selected = m.crumbIndex
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, ui.Breadcrumb(m.currentDir, m.breadcrumbWidth(), selected), header)
+}
+
+func (m model) breadcrumbWidth() int {
+	if m.width > 0 {
+		return m.width
+	}
+	return 80
+}
+
+// actionTabAt returns the action whose tab is rendered at column x of actionTabsView.
+func (m model) actionTabAt(x int) (string, bool) {
+	start := 0
+	for _, a := range buildActions(m.config) {
+		w := lipgloss.Width("[" + a + "]")
+		if x >= start && x < start+w {
+			return a, true
+		}
+		start += w + 1 // Tabs are joined with a single space
+	}
+	return "", false
+}
+
 func (m model) actionTabsView() string {
 	actions := buildActions(m.config)
 	var tabs []string
@@ -1084,7 +1973,7 @@ func (m model) configView() string {
 	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255"))
 
 	var lines []string
-	for i := 0; i < 5; i++ {
+	for i := 0; i < 6; i++ {
 		prefix := "  "
 		if i == m.configField {
 			prefix = "> "
@@ -1161,6 +2050,15 @@ func (m model) configView() string {
 				}
 				lines = append(lines, prefix+key+valueStyle.Render(strings.Join(names, ", ")))
 			}
+		case 5:
+			key := keyStyle.Render("Auto tags: ")
+			if m.configEditing && m.configField == 5 {
+				lines = append(lines, prefix+key+valueStyle.Render(m.configInput.View()))
+			} else if m.config.AutoTags == "" {
+				lines = append(lines, prefix+key+valueStyle.Render("(none, e.g. go.mod=go; package.json=node; ~/work=work)"))
+			} else {
+				lines = append(lines, prefix+key+valueStyle.Render(m.config.AutoTags))
+			}
 		}
 	}
 
@@ -1173,11 +2071,28 @@ func (m model) configView() string {
 	)
 }
 
+// tagDefField returns the value of the definition field edited at step.
+func tagDefField(def store.TagDef, step int) string {
+	switch step {
+	case 1:
+		return def.Description
+	case 2:
+		return def.Color
+	case 3:
+		return def.Icon
+	case 4:
+		return def.Action
+	}
+	return ""
+}
+
 func (m model) tagsView() string {
 	title := lipgloss.NewStyle().Bold(true).Render("Tags")
 	pathLine := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(m.tagPath)
-	help := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("A: add • D: delete • Esc: back • Enter: save tag")
+	help := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("A: add • E: edit definition • D: delete • Esc: back • Enter: save tag")
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 
+	styles := m.tagStyles()
 	var lines []string
 	if len(m.tagList) == 0 {
 		lines = append(lines, "(no tags)")
@@ -1187,13 +2102,31 @@ func (m model) tagsView() string {
 			if i == m.tagSelected {
 				prefix = "> "
 			}
-			lines = append(lines, prefix+t)
+			line := prefix + ui.TagBadge(t, styles[t])
+			def := m.tagDefs[t]
+			if def.Description != "" {
+				line += "  " + detailStyle.Render(def.Description)
+			}
+			if def.Action != "" {
+				line += "  " + detailStyle.Render("→ "+def.Action)
+			}
+			lines = append(lines, line)
 		}
 	}
 
 	editLine := ""
 	if m.tagEditing {
 		editLine = lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Render("Add tag: ") + m.tagInput.View()
+		if m.tagErr != nil {
+			editLine += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Render(m.tagErr.Error())
+		}
+	}
+	if m.tagDefStep > 0 {
+		labels := []string{"", "Description: ", "Color (e.g. 42 or #ff8800): ", "Icon: ", "Default action: "}
+		editLine = lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Render("@"+m.tagDef.Name+" "+labels[m.tagDefStep]) + m.tagInput.View()
+		if m.tagDefStep == 4 {
+			editLine += "\n" + detailStyle.Render("  one of: "+strings.Join(buildActions(m.config), ", ")+" (empty: none)")
+		}
 	}
 
 	return lipgloss.JoinVertical(
@@ -1210,12 +2143,17 @@ func main() {
 	// CLI Flags
 	addTag := flag.String("add", "", "Add current directory to a tag")
 	startAction := flag.String("action", "", "Start with action: terminal|explorer|editor|copy")
+	projects := flag.Bool("projects", false, "Search only detected project roots, ranked by visits inside them; without a query, list them")
+	dbFlag := flag.String("db", "", "Database path (\":memory:\" for an ephemeral session); defaults to $NAVI_DATA_DIR, $XDG_DATA_HOME/navi or ~/.local/share/navi")
 	flag.Parse()
 
 	// Init DB
-	home, _ := os.UserHomeDir()
-	dbPath := filepath.Join(home, ".local", "share", "navi", "navi.db")
-	db, err := store.InitDB(dbPath)
+	dbPath, err := store.ResolveDBPath(*dbFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate db: %v\n", err)
+		os.Exit(1)
+	}
+	db, err := store.Open(dbPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to init db: %v\n", err)
 		os.Exit(1)
@@ -1224,8 +2162,13 @@ func main() {
 
 	// Handle CLI Commands
 	if *addTag != "" {
+		*addTag = strings.TrimPrefix(*addTag, "@")
+		if err := store.ValidateTagName(*addTag); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to add to tag: %v\n", err)
+			os.Exit(1)
+		}
 		cwd, _ := os.Getwd()
-		err := store.AddPathToTag(db, *addTag, cwd)
+		err := db.AddPathToTag(*addTag, cwd)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to add to tag: %v\n", err)
 			os.Exit(1)
@@ -1234,12 +2177,49 @@ func main() {
 		return
 	}
 
-	// Non-interactive: if args provided, return best match and exit
+	// Subcommands (navi history ...)
+	if args := flag.Args(); len(args) > 0 {
+		if run, ok := subcommands[args[0]]; ok {
+			if err := run(db, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "navi %s: %v\n", args[0], err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// Projects: list them, or print the best match for the query
+	if *projects {
+		ranked := rankedProjects(db)
+		if len(flag.Args()) == 0 {
+			for _, p := range ranked {
+				fmt.Println(p)
+			}
+			return
+		}
+		results := search.FuzzyHierarchical(ranked, strings.Join(flag.Args(), " "))
+		if len(results) == 0 {
+			os.Exit(1)
+		}
+		fmt.Println(results[0].Path)
+		return
+	}
+
+	// Non-interactive: if args provided, return best match and exit.
+	// An alias name (`navi dots` or `navi :dots`) resolves exactly.
 	if args := flag.Args(); len(args) > 0 {
+		if len(args) == 1 {
+			name, _ := aliasQuery(args[0])
+			if path, _ := db.GetAlias(name); path != "" {
+				fmt.Println(path)
+				return
+			}
+		}
 		query := strings.Join(args, " ")
 		cwd, _ := os.Getwd()
 		files := buildSearchList(db, cwd)
 		results := search.FuzzyHierarchical(files, query)
+		promotePicks(db, results, query, cwd)
 		if len(results) == 0 {
 			os.Exit(1)
 		}
@@ -1262,7 +2242,7 @@ func main() {
 		}
 	}
 
-	p := tea.NewProgram(initialModel(db, cfg), tea.WithAltScreen())
+	p := tea.NewProgram(initialModel(db, cfg), tea.WithAltScreen(), tea.WithMouseCellMotion())
 	finalModel, err := p.Run()
 	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/search"
+	"github.com/montrey/navi/store"
+	"github.com/montrey/navi/ui"
+	"github.com/montrey/navi/vcs"
+)
+
+// withBadges applies loadBadges at once, as the TUI does when it finishes.
+func withBadges(m model) model {
+	m.setBadges(m.loadBadges()().(badgesMsg))
+	return m
+}
+
+func TestLoadInitialFilesOrdering(t *testing.T) {
+	db := store.NewMemStore()
+	now := time.Now()
+	db.Now = func() time.Time { return now }
+	_ = db.UpdateFrecency("/recent/old")
+	now = now.Add(time.Minute)
+	_ = db.UpdateFrecency("/recent/new")
+	_ = db.AddPathToTag("work", "/tagged")
+
+	msg := loadInitialFiles(db, "")()
+	files, ok := msg.(filesLoadedMsg)
+	if !ok {
+		t.Fatalf("expected filesLoadedMsg, got %T", msg)
+	}
+
+	got := search.EntryPaths(files)
+	want := []string{"/tagged", "/recent/new", "/recent/old"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestContextFrecency(t *testing.T) {
+	db := store.NewMemStore()
+	now := time.Now()
+	db.Now = func() time.Time { return now }
+	_ = recordVisit(db, "/svc/handler.go", "/svc")
+	now = now.Add(time.Minute)
+	for i := 0; i < 5; i++ {
+		_ = recordVisit(db, "/popular", "/elsewhere")
+	}
+
+	// Inside /svc its own pick comes first despite being less popular
+	got := search.EntryPaths(loadInitialFiles(db, "/svc")().(filesLoadedMsg))
+	if len(got) != 2 || got[0] != "/svc/handler.go" {
+		t.Errorf("expected /svc/handler.go first in /svc, got %v", got)
+	}
+
+	// Search results are ranked the same way, even though /popular reaches
+	// the frecency cap on its own
+	rank := func(context string) []string {
+		m := initialModel(db, appConfig{})
+		m.context = context
+		m = withBadges(m)
+		m.input.SetValue("o")
+		updated, _ := m.Update(searchDoneMsg{{Path: "/popular"}, {Path: "/svc/handler.go"}})
+		var order []string
+		for _, item := range updated.(model).list.Items {
+			order = append(order, item.Path)
+		}
+		return order
+	}
+	if got := rank("/svc"); len(got) != 2 || got[0] != "/svc/handler.go" {
+		t.Errorf("expected the context's pick to outrank the globally popular path, got %v", got)
+	}
+	// Unseen contexts fall back to global frecency
+	if got := rank("/unseen"); len(got) != 2 || got[0] != "/popular" {
+		t.Errorf("expected global frecency in an unseen context, got %v", got)
+	}
+}
+
+func TestSetActiveTagAction(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.SetTagDef(store.TagDef{Name: "notes", Action: "editor"})
+	m := initialModel(db, loadConfig(db))
+	m = withBadges(m)
+	m.config.DefaultAction = "explorer"
+
+	m.setTagQuery(store.TagQuery{All: [][]string{{"notes"}}})
+	if m.config.DefaultAction != "editor" {
+		t.Errorf("expected @notes to select editor, got %q", m.config.DefaultAction)
+	}
+	m.setTagQuery(store.TagQuery{})
+	if m.config.DefaultAction != "explorer" {
+		t.Errorf("expected leaving @notes to restore explorer, got %q", m.config.DefaultAction)
+	}
+
+	m.setTagQuery(store.TagQuery{All: [][]string{{"notes"}}})
+	m.config.DefaultAction = "terminal" // picked with Tab
+	m.setTagQuery(store.TagQuery{})
+	if m.config.DefaultAction != "terminal" {
+		t.Errorf("expected the user's pick to survive leaving the tag, got %q", m.config.DefaultAction)
+	}
+}
+
+func TestTagCompletion(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.AddPathToTag("work", "/w")
+	_ = db.AddPathToTag("personal", "/p")
+	m := initialModel(db, loadConfig(db))
+	m = withBadges(m)
+
+	m.input.SetValue("@wo")
+	m, _ = m.inputChanged(m.input.Value())
+	if len(m.tagCompletions) == 0 || m.tagCompletions[0] != "work" {
+		t.Fatalf("expected work to complete @wo, got %v", m.tagCompletions)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = next.(model)
+	if m.input.Value() != "@work " || m.activeTag != "work" || len(m.unknownTags) > 0 {
+		t.Errorf("expected Tab to enter @work, got input %q tag %q unknown %v", m.input.Value(), m.activeTag, m.unknownTags)
+	}
+	if len(m.tagCompletions) != 0 {
+		t.Errorf("expected completions to close, got %v", m.tagCompletions)
+	}
+
+	// Completing a second term keeps the first
+	m.input.SetValue("@work -@pe")
+	m, _ = m.inputChanged(m.input.Value())
+	if len(m.tagCompletions) == 0 || m.tagCompletions[0] != "personal" {
+		t.Fatalf("expected personal to complete -@pe, got %v", m.tagCompletions)
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if m.input.Value() != "@work -@personal " || m.activeTag != "work -@personal" {
+		t.Errorf("expected Enter to add -@personal, got input %q tag %q", m.input.Value(), m.activeTag)
+	}
+
+	m.input.SetValue("@work @nope ")
+	m, _ = m.inputChanged(m.input.Value())
+	if len(m.unknownTags) != 1 || m.unknownTags[0] != "nope" {
+		t.Errorf("expected @nope to be flagged as unknown, got %v", m.unknownTags)
+	}
+	m.input.SetValue("plain")
+	m, _ = m.inputChanged(m.input.Value())
+	if len(m.unknownTags) > 0 {
+		t.Errorf("expected leaving the tag to clear the unknown state")
+	}
+}
+
+func TestProjectsScope(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	web := filepath.Join(root, "web")
+	for _, dir := range []string{filepath.Join(api, ".git"), web} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(web, "go.mod"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db := store.NewMemStore()
+	loadFiles(db, root)()
+	_ = recordVisit(db, filepath.Join(web, "go.mod"), "")
+	_ = db.AddProjects([]string{filepath.Join(root, "gone")})
+
+	msg := loadTagFiles(db, nil, root, store.TagQuery{All: [][]string{{projectsScope}}})()
+	got := search.EntryPaths(msg.(filesLoadedMsg))
+	if len(got) != 2 || got[0] != web || got[1] != api {
+		t.Errorf("expected [%s %s], got %v", web, api, got)
+	}
+	_, _ = sweepDeadProjects(db)
+	if projects, _ := db.GetProjects(); len(projects) != 2 {
+		t.Errorf("expected the sweep to forget the vanished project, got %v", projects)
+	}
+}
+
+func TestTagScopeProjectExclusions(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{".git", "api", "legacy"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	doc := `{"tags": {"archived": "legacy", "work": "api"}}`
+	if err := os.WriteFile(filepath.Join(root, ".navi.json"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	api, legacy := filepath.Join(root, "api"), filepath.Join(root, "legacy")
+
+	db := store.NewMemStore()
+	_ = db.AddPathToTag("work", legacy)
+
+	// legacy is stored as @work but archived by the project file; api is
+	// @work through the project file only
+	q, _ := store.ParseTagQuery("@work -@archived ")
+	got := search.EntryPaths(loadTagFiles(db, nil, root, q)().(filesLoadedMsg))
+	if len(got) != 1 || got[0] != api {
+		t.Errorf("expected [%s], got %v", api, got)
+	}
+}
+
+func TestPseudoScopeCombination(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.AddPathToTag("work", t.TempDir())
+	m := initialModel(db, loadConfig(db))
+	m = withBadges(m)
+
+	m.input.SetValue("@projects @work ")
+	m, cmd := m.inputChanged(m.input.Value())
+	if m.tagQueryErr == nil || !strings.Contains(m.View(), "@projects can't be combined") {
+		t.Errorf("expected @projects @work to be rejected, got %v", m.tagQueryErr)
+	}
+	if files := cmd().(filesLoadedMsg); len(files) != 0 {
+		t.Errorf("expected no results, got %v", files)
+	}
+
+	m.input.SetValue("@work ")
+	if m, _ = m.inputChanged(m.input.Value()); m.tagQueryErr != nil {
+		t.Errorf("expected @work alone to be accepted, got %v", m.tagQueryErr)
+	}
+}
+
+func TestBrokenProjectTagFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".navi.toml"), []byte("[tags]\napi = 42\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(store.NewMemStore(), appConfig{})
+	m.currentDir = root
+	m = withBadges(m)
+	if m.err == nil || !strings.Contains(m.View(), ".navi.toml") {
+		t.Errorf("expected the parse error in the status line, got %v", m.err)
+	}
+}
+
+func TestDirtyScope(t *testing.T) {
+	if !vcs.Available() {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	notes := filepath.Join(repo, "notes.txt")
+	if err := os.WriteFile(notes, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db := store.NewMemStore()
+	_ = db.AddPathToTag("work", repo)
+	cache := vcs.NewCache()
+	q := store.TagQuery{All: [][]string{{dirtyScope}}}
+	msg := loadTagFiles(db, cache, t.TempDir(), q)()
+	got := search.EntryPaths(msg.(filesLoadedMsg))
+	if len(got) != 1 || got[0] != notes {
+		t.Errorf("expected [%s], got %v", notes, got)
+	}
+
+	// The tree draws the untracked badge on the absolute result
+	m := initialModel(db, appConfig{})
+	m.git = cache
+	m.isInitialLoad = false
+	m.setTagQuery(q)
+	m.input.SetValue("@dirty ")
+	updated, cmd := m.Update(msg)
+	for _, c := range cmd().(tea.BatchMsg) {
+		updated, _ = updated.(model).Update(c())
+	}
+	view := updated.(model).tree.View()
+	if !strings.Contains(view, " ?") {
+		t.Errorf("expected an untracked badge on the notes.txt row, got\n%s", view)
+	}
+}
+
+func TestQueryPicksRankFirst(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.RecordQueryPick("cfg", "/deploy/config/prod.yaml")
+
+	m := initialModel(db, appConfig{})
+	m.git = nil
+	m.input.SetValue("cf")
+	results := search.FuzzyHierarchical([]string{"/cf/other", "/deploy/config/prod.yaml"}, "cf")
+	updated, _ := m.Update(searchDoneMsg(results))
+	items := updated.(model).list.Items
+	if len(items) != 2 || items[0].Path != "/deploy/config/prod.yaml" {
+		t.Errorf("expected the remembered pick first, got %v", items)
+	}
+}
+
+func TestPins(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.UpdateFrecency("/recent")
+	_ = db.AddPathToTag("work", "/tagged")
+	for _, p := range []string{"/pins/a", "/pins/b", "/pins/c"} {
+		_ = db.AddPin(p)
+	}
+	if err := runPin(db, []string{"mv", "3", "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := search.EntryPaths(loadInitialFiles(db, "")().(filesLoadedMsg))
+	want := []string{"/pins/c", "/pins/a", "/pins/b", "/tagged", "/recent"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	m := initialModel(db, appConfig{})
+	m = withBadges(m)
+	if p, ok := m.pinPath("2"); !ok || p != "/pins/a" {
+		t.Errorf("expected 2 to jump to /pins/a, got %q", p)
+	}
+	if _, ok := m.pinPath("4"); ok {
+		t.Errorf("expected no pin behind 4")
+	}
+
+	// A plain digit starts a search; Alt+digit opens the pin
+	var started []string
+	startCommand = func(cmd *exec.Cmd) error {
+		started = append(started, cmd.Args[len(cmd.Args)-1])
+		return nil
+	}
+	t.Cleanup(func() { startCommand = (*exec.Cmd).Start })
+	m.config = appConfig{DefaultAction: "editor", EditorCmd: `edit "{path}"`}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	if um := updated.(model); um.input.Value() != "1" || um.selectedPath != "" {
+		t.Errorf("expected 1 to be typed into the search, got input %q and selection %q", um.input.Value(), um.selectedPath)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2"), Alt: true})
+	if um := updated.(model); um.selectedPath != "/pins/a" {
+		t.Errorf("expected Alt+2 to open /pins/a, got %q", um.selectedPath)
+	}
+	if len(started) != 1 || started[0] != `edit "/pins/a"` {
+		t.Errorf("expected the editor to be started on /pins/a, got %q", started)
+	}
+
+	if err := runPin(db, []string{"rm", "1", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if pins, _ := db.GetPins(); len(pins) != 1 || pins[0] != "/pins/b" {
+		t.Errorf("expected numbers to refer to the listed order, got %v", pins)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	db := store.NewMemStore()
+	dots := t.TempDir()
+	if err := runAlias(db, []string{"set", "dots", dots}); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.SetAlias("docs", "/srv/docs")
+	_ = db.SetAlias("do", "/srv/do")
+	for _, name := range []string{"pin", "@x", "a/b", ":x"} {
+		if err := runAlias(db, []string{"set", name, dots}); err == nil {
+			t.Errorf("expected alias name %q to be rejected", name)
+		}
+	}
+
+	if names := slices.Sorted(maps.Keys(subcommands)); !slices.Equal(names, reservedNames) {
+		t.Errorf("expected reservedNames to list the subcommands %v, got %v", names, reservedNames)
+	}
+
+	// Imported aliases are validated too
+	state := filepath.Join(t.TempDir(), "state.json")
+	_ = os.WriteFile(state, []byte(`{"version": 1, "aliases": {"history": "/srv"}}`), 0644)
+	if err := runImport(db, []string{state}); err == nil {
+		t.Errorf("expected an imported alias named after a command to be rejected")
+	}
+	if p, _ := db.GetAlias("history"); p != "" {
+		t.Errorf("expected the rejected import to change nothing, got alias %q", p)
+	}
+
+	aliases, _ := db.GetAliases()
+	if got := matchAliases(aliases, "do"); strings.Join(got, " ") != "/srv/do /srv/docs "+dots {
+		t.Errorf("expected the exact alias first, got %v", got)
+	}
+
+	// ":dots" shows exactly the aliased path, whatever the fuzzy results were
+	m := initialModel(db, appConfig{})
+	m.git = nil
+	m.input.SetValue(":dots")
+	m, _ = m.inputChanged(":dots")
+	updated, _ := m.Update(searchDoneMsg(search.FuzzyHierarchical([]string{"/other/dots"}, "dots")))
+	items := updated.(model).list.Items
+	if len(items) != 1 || items[0].Path != dots || !items[0].Entry.IsDir() {
+		t.Errorf("expected only %s, got %v", dots, items)
+	}
+}
+
+func TestShortcutsFitWidth(t *testing.T) {
+	m := initialModel(store.NewMemStore(), appConfig{})
+	for _, width := range []int{80, 120} {
+		m.width = width
+		line := m.shortcutsView()
+		if w := lipgloss.Width(line); w > width || !strings.HasPrefix(line, "Enter: open") {
+			t.Errorf("expected the shortcuts to fit %d columns, got %d: %q", width, w, line)
+		}
+	}
+	m.crumbFocus = true
+	if line := m.shortcutsView(); !strings.Contains(line, "Left/Right: choose") || strings.Contains(line, "Ctrl+T") {
+		t.Errorf("expected breadcrumb keys while choosing a segment, got %q", line)
+	}
+}
+
+func TestBreadcrumbKeys(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(store.NewMemStore(), appConfig{})
+	m.currentDir = dir
+
+	key := func(k tea.KeyType) {
+		updated, _ := m.Update(tea.KeyMsg{Type: k})
+		m = updated.(model)
+	}
+
+	// Ctrl+B starts at the parent; Left moves up once more, Enter re-roots
+	key(tea.KeyCtrlB)
+	if !m.crumbFocus || ui.Crumbs(dir)[m.crumbIndex].Path != filepath.Join(root, "a") {
+		t.Fatalf("expected Ctrl+B to select the parent, got focus %v index %d", m.crumbFocus, m.crumbIndex)
+	}
+	key(tea.KeyLeft)
+	key(tea.KeyEnter)
+	if m.crumbFocus || m.currentDir != root {
+		t.Errorf("expected Enter to re-root at %s, got %s (focus %v)", root, m.currentDir, m.crumbFocus)
+	}
+	if len(m.dirBack) != 1 || m.dirBack[0] != dir {
+		t.Errorf("expected Alt+Left to lead back to %s, got %v", dir, m.dirBack)
+	}
+
+	// Esc cancels without moving
+	key(tea.KeyCtrlB)
+	key(tea.KeyEsc)
+	if m.crumbFocus || m.currentDir != root {
+		t.Errorf("expected Esc to cancel, got %s (focus %v)", m.currentDir, m.crumbFocus)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/store"
+)
+
+// maxPinKeys is how many pins the number keys jump to: Alt+1-9 while
+// browsing, 1-9 on the pins screen.
+const maxPinKeys = 9
+
+// pinScreen is the state of the pins screen (Ctrl+P).
+type pinScreen struct {
+	selected  int
+	candidate string // Result selected when the screen opened; A pins it
+	err       error
+}
+
+// openPins switches to the pins screen, offering to pin candidate.
+func (m model) openPins(candidate string) model {
+	m.pinScreen = pinScreen{candidate: candidate}
+	m.pins, _ = m.db.GetPins()
+	m.mode = modePins
+	return m
+}
+
+// pinPath returns the pin a number key selects, "1" being the first.
+func (m model) pinPath(key string) (string, bool) {
+	n, err := strconv.Atoi(key)
+	if err != nil || n < 1 || n > maxPinKeys || n > len(m.pins) {
+		return "", false
+	}
+	return m.pins[n-1], true
+}
+
+func (m model) updatePins(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	ps := &m.pinScreen
+	ps.err = nil
+
+	// Swaps the selected pin with the one delta places away
+	move := func(delta int) {
+		j := ps.selected + delta
+		if j < 0 || j >= len(m.pins) {
+			return
+		}
+		m.pins[ps.selected], m.pins[j] = m.pins[j], m.pins[ps.selected]
+		ps.err = m.db.SetPins(m.pins)
+		ps.selected = j
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.mode = modeBrowse
+		return m, nil
+	case "up":
+		if ps.selected > 0 {
+			ps.selected--
+		}
+	case "down":
+		if ps.selected < len(m.pins)-1 {
+			ps.selected++
+		}
+	case "shift+up", "K":
+		move(-1)
+	case "shift+down", "J":
+		move(1)
+	case "a":
+		if ps.candidate != "" {
+			ps.err = m.db.AddPin(ps.candidate)
+			m.pins, _ = m.db.GetPins()
+			ps.selected = clampIndex(slices.Index(m.pins, ps.candidate), len(m.pins))
+		}
+	case "d":
+		if ps.selected < len(m.pins) {
+			_, ps.err = m.db.RemovePin(m.pins[ps.selected])
+			m.pins, _ = m.db.GetPins()
+			ps.selected = clampIndex(ps.selected, len(m.pins))
+		}
+	case "enter":
+		if ps.selected < len(m.pins) {
+			return m.openPath(m.pins[ps.selected])
+		}
+	default:
+		if path, ok := m.pinPath(msg.String()); ok {
+			return m.openPath(path)
+		}
+	}
+	return m, nil
+}
+
+func (m model) pinsView() string {
+	ps := m.pinScreen
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	title := lipgloss.NewStyle().Bold(true).Render("Pins")
+	help := dim.Render("Enter/1-9: open • A: pin selected result • D: unpin • Shift+Up/Down: move • Esc: back")
+
+	var lines []string
+	if len(m.pins) == 0 {
+		lines = append(lines, "(no pins)")
+	}
+	for i, p := range m.pins {
+		prefix := "  "
+		if i == ps.selected {
+			prefix = "> "
+		}
+		key := " "
+		if i < maxPinKeys {
+			key = strconv.Itoa(i + 1)
+		}
+		line := prefix + dim.Render(key) + " " + p
+		if _, err := os.Stat(p); err != nil {
+			line += " " + lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Render("(missing)")
+		}
+		lines = append(lines, line)
+	}
+
+	status := ""
+	switch {
+	case ps.err != nil:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Render(ps.err.Error())
+	case ps.candidate != "" && !slices.Contains(m.pins, ps.candidate):
+		status = dim.Render("A pins " + ps.candidate)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		help,
+		strings.Join(lines, "\n"),
+		status,
+	)
+}
+
+// resolvePinArg turns a `navi pin` argument into a pinned path: a 1-based
+// position in pins, or a path made absolute.
+func resolvePinArg(pins []string, arg string) string {
+	if n, err := strconv.Atoi(arg); err == nil && n >= 1 && n <= len(pins) {
+		return pins[n-1]
+	}
+	if abs, err := filepath.Abs(arg); err == nil {
+		return abs
+	}
+	return arg
+}
+
+// runPin implements `navi pin ls|add|rm|mv`.
+func runPin(db store.Store, args []string) error {
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+	pins, err := db.GetPins()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "ls", "list":
+		for i, p := range pins {
+			fmt.Printf("%2d  %s\n", i+1, p)
+		}
+		return nil
+
+	case "add":
+		paths := args[1:]
+		if len(paths) == 0 {
+			cwd, _ := os.Getwd()
+			paths = []string{cwd}
+		}
+		for _, p := range paths {
+			if abs, err := filepath.Abs(p); err == nil {
+				p = abs
+			}
+			if err := db.AddPin(p); err != nil {
+				return err
+			}
+			fmt.Printf("Pinned %s\n", p)
+		}
+		return nil
+
+	case "rm", "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: navi pin rm <path|number>...")
+		}
+		// Resolve every number before removing anything so they all refer to the listed order
+		var targets []string
+		for _, arg := range args[1:] {
+			targets = append(targets, resolvePinArg(pins, arg))
+		}
+		for _, p := range targets {
+			ok, err := db.RemovePin(p)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Fprintf(os.Stderr, "not pinned: %s\n", p)
+				continue
+			}
+			fmt.Printf("Unpinned %s\n", p)
+		}
+		return nil
+
+	case "mv", "move":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: navi pin mv <path|number> <position>")
+		}
+		p := resolvePinArg(pins, args[1])
+		i := slices.Index(pins, p)
+		if i < 0 {
+			return fmt.Errorf("not pinned: %s", p)
+		}
+		to, err := strconv.Atoi(args[2])
+		if err != nil || to < 1 {
+			return fmt.Errorf("invalid position %q", args[2])
+		}
+		to = min(to, len(pins))
+		pins = slices.Delete(pins, i, i+1)
+		pins = slices.Insert(pins, to-1, p)
+		return db.SetPins(pins)
+	}
+
+	return fmt.Errorf("unknown pin command %q (want ls, add, rm or mv)", args[0])
+}
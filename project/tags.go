@@ -0,0 +1,147 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TagFileNames are the project tag files looked up in each directory, in order.
+var TagFileNames = []string{".navi.toml", ".navi.json"}
+
+// FindTagFile walks up from dir looking for a project tag file. The walk
+// stops at the repository root (the first directory containing .git) or at
+// the filesystem root.
+func FindTagFile(dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	for {
+		for _, name := range TagFileNames {
+			p := filepath.Join(dir, name)
+			if info, err := os.Stat(p); err == nil && !info.IsDir() {
+				return p, true
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Tags finds the project tag file for dir and loads it.
+// A missing file is not an error; it just means no project tags.
+func Tags(dir string) (map[string][]string, error) {
+	p, ok := FindTagFile(dir)
+	if !ok {
+		return nil, nil
+	}
+	return LoadTags(p)
+}
+
+// LoadTags parses a project tag file. Tag names are returned without the
+// leading '@' and repo-relative paths are resolved against the file's directory.
+//
+// .navi.toml:
+//
+//	[tags]
+//	api = "services/api"
+//	"@services" = ["services/api", "services/web"]
+//
+// .navi.json:
+//
+//	{"tags": {"api": "services/api", "@services": ["services/api", "services/web"]}}
+func LoadTags(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var raw map[string][]string
+	if strings.HasSuffix(path, ".json") {
+		raw, err = parseJSONTags(data)
+	} else {
+		raw, err = parseTOMLTags(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	root := filepath.Dir(path)
+	tags := make(map[string][]string, len(raw))
+	for name, paths := range raw {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+		if name == "" {
+			continue
+		}
+		for _, p := range paths {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(root, p)
+			}
+			tags[name] = append(tags[name], filepath.Clean(p))
+		}
+	}
+	return tags, nil
+}
+
+func parseJSONTags(data []byte) (map[string][]string, error) {
+	var doc struct {
+		Tags map[string]json.RawMessage `json:"tags"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	tags := make(map[string][]string, len(doc.Tags))
+	for name, value := range doc.Tags {
+		var one string
+		if err := json.Unmarshal(value, &one); err == nil {
+			tags[name] = []string{one}
+			continue
+		}
+		var many []string
+		if err := json.Unmarshal(value, &many); err != nil {
+			return nil, fmt.Errorf("tag %q: expected a path or a list of paths", name)
+		}
+		tags[name] = many
+	}
+	return tags, nil
+}
+
+func parseTOMLTags(data []byte) (map[string][]string, error) {
+	var doc struct {
+		Tags map[string]any `toml:"tags"`
+	}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	tags := make(map[string][]string, len(doc.Tags))
+	for name, value := range doc.Tags {
+		switch v := value.(type) {
+		case string:
+			tags[name] = []string{v}
+		case []any:
+			for _, item := range v {
+				p, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("tag %q: expected a path or a list of paths", name)
+				}
+				tags[name] = append(tags[name], p)
+			}
+		default:
+			return nil, fmt.Errorf("tag %q: expected a path or a list of paths", name)
+		}
+	}
+	return tags, nil
+}
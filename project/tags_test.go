@@ -0,0 +1,124 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTagsTOML(t *testing.T) {
+	root := t.TempDir()
+	_ = os.Mkdir(filepath.Join(root, ".git"), 0755)
+	sub := filepath.Join(root, "services", "api")
+	_ = os.MkdirAll(sub, 0755)
+	doc := `# project tags
+[other]
+ignored = "x"
+
+[tags]
+api = "services/api" # inline comment
+"@services" = [
+  "services/api",
+  'services/web',
+]
+`
+	if err := os.WriteFile(filepath.Join(root, ".navi.toml"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := Tags(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"api":      {filepath.Join(root, "services/api")},
+		"services": {filepath.Join(root, "services/api"), filepath.Join(root, "services/web")},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("expected %v, got %v", want, tags)
+	}
+}
+
+func TestTagsJSON(t *testing.T) {
+	root := t.TempDir()
+	doc := `{"tags": {"docs": "docs", "@web": ["web", "/abs/web"]}}`
+	if err := os.WriteFile(filepath.Join(root, ".navi.json"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := Tags(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"docs": {filepath.Join(root, "docs")},
+		"web":  {filepath.Join(root, "web"), "/abs/web"},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("expected %v, got %v", want, tags)
+	}
+}
+
+func TestFindTagFileStopsAtRepoRoot(t *testing.T) {
+	outer := t.TempDir()
+	_ = os.WriteFile(filepath.Join(outer, ".navi.toml"), []byte("[tags]\nx = \"x\"\n"), 0644)
+	repo := filepath.Join(outer, "repo")
+	_ = os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+
+	if p, ok := FindTagFile(repo); ok {
+		t.Errorf("expected no tag file inside the repo, found %s", p)
+	}
+	if _, ok := FindTagFile(outer); !ok {
+		t.Errorf("expected to find the tag file in %s", outer)
+	}
+}
+
+func TestTagsTOMLSpecialCharacters(t *testing.T) {
+	doc := `[settings]
+pattern = "[tags]"
+nested = [[1, 2], ["]"]]
+inline = { a = "x", b = [1] }
+text = """
+[tags]
+not = "a tag"
+"""
+when = 1979-05-27 07:32:00
+
+[tags]
+"a=b,c" = "dir,with=chars" # comment
+'lit,eral' = ['x,y', "p=q", "esc\"aped#"]
+"@web" = [
+  "web", # first
+  "/abs/web",
+]
+`
+	tags, err := parseTOMLTags([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"a=b,c":    {"dir,with=chars"},
+		"lit,eral": {"x,y", "p=q", `esc"aped#`},
+		"@web":     {"web", "/abs/web"},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("expected %v, got %v", want, tags)
+	}
+}
+
+func TestTagsTOMLRejectsUnsupported(t *testing.T) {
+	for name, doc := range map[string]string{
+		"number":             "[tags]\napi = 42\n",
+		"table":              "[tags]\na.b = \"x\"\n",
+		"trailing garbage":   "[tags]\napi = \"x\" y\n",
+		"unterminated array": "[tags]\napi = [\"x\",\n",
+		"unterminated str":   "[tags]\napi = \"x\n",
+		"non-string item":    "[tags]\napi = [\"x\", 1]\n",
+		"missing comma":      "[tags]\napi = [\"x\" \"y\"]\n",
+	} {
+		if tags, err := parseTOMLTags([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error, got %v", name, tags)
+		}
+	}
+}
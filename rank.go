@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/montrey/navi/search"
+	"github.com/montrey/navi/store"
+	"github.com/montrey/navi/vcs"
+)
+
+const (
+	dirtyBonus       = 15 // Score added to results with uncommitted changes
+	recentBonus      = 5  // Score added to results modified within recentWindow
+	recentWindow     = 24 * time.Hour
+	maxFrecencyBonus = 20 // Cap on the score added for global frecency
+	maxContextBonus  = 20 // Cap on the score added for frecency in the current context
+	contextWeight    = 5  // How much more a visit from the current context counts
+)
+
+// rankBonus returns the score added to a search result on top of its fuzzy
+// score: frecency (see frecencyBonus), plus boosts for dirty results and
+// results modified recently.
+func (m model) rankBonus(states map[string]vcs.State, now time.Time) func(string) int {
+	return func(path string) int {
+		bonus := m.frecencyBonus(resolveSelectedPath(path, m.currentDir))
+		if states[path].Dirty() {
+			bonus += dirtyBonus
+		}
+		if mt := m.entries[path].ModTime; !mt.IsZero() && now.Sub(mt) < recentWindow {
+			bonus += recentBonus
+		}
+		return bonus
+	}
+}
+
+// frecencyBonus scores path by its global frecency and, separately, by its
+// frecency from the current context weighted by contextWeight. Each part has
+// its own cap, so a globally popular path can't crowd out the context's picks.
+func (m model) frecencyBonus(path string) int {
+	global := min(int(m.frecency[path]), maxFrecencyBonus)
+	context := min(int(contextWeight*m.contextFrecency[path]), maxContextBonus)
+	return global + context
+}
+
+// promotePicks moves the results picked before for query (or a longer query
+// starting with it) to the front, best pick first. The rest keep their order.
+// Relative result paths are resolved against baseDir.
+func promotePicks(db store.Store, results []search.Result, query, baseDir string) {
+	picks, _ := db.GetQueryPicks(query)
+	if len(picks) == 0 {
+		return
+	}
+	order := make(map[string]int, len(picks))
+	for i, p := range picks {
+		order[p.Path] = i
+	}
+	rank := func(r search.Result) int {
+		if i, ok := order[resolveSelectedPath(r.Path, baseDir)]; ok {
+			return i
+		}
+		return len(picks)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return rank(results[i]) < rank(results[j])
+	})
+}
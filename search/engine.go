@@ -1,6 +1,7 @@
 package search
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/sahilm/fuzzy"
@@ -50,6 +51,15 @@ func FuzzyHierarchical(paths []string, query string) []Result {
 	return results
 }
 
+// Boost adds bonus(path) to the score of every result and re-sorts them,
+// keeping the original order among equal scores.
+func Boost(results []Result, bonus func(path string) int) {
+	for i := range results {
+		results[i].Score += bonus(results[i].Path)
+	}
+	sort.Stable(ByScore(results))
+}
+
 // Helper to manually partial sort if we add custom scoring later
 type ByScore []Result
 
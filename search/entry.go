@@ -0,0 +1,85 @@
+package search
+
+import (
+	"io/fs"
+	"os"
+	"time"
+)
+
+// EntryType is the kind of filesystem object an Entry refers to.
+type EntryType int
+
+const (
+	TypeUnknown EntryType = iota // Not stat'ed, or the path no longer exists
+	TypeFile
+	TypeDir
+	TypeSymlink
+)
+
+// Entry is a typed search candidate.
+type Entry struct {
+	Path    string
+	Type    EntryType
+	Size    int64
+	ModTime time.Time
+
+	// Symlink details (only set when Type == TypeSymlink)
+	Target     string
+	TargetType EntryType // TypeUnknown if the link is broken
+
+	// IsProject is set by Walk on directories containing a project marker
+	IsProject bool
+}
+
+// IsDir reports whether the entry is a directory or a symlink to one.
+func (e Entry) IsDir() bool {
+	return e.Type == TypeDir || (e.Type == TypeSymlink && e.TargetType == TypeDir)
+}
+
+// IsBroken reports whether the entry is a symlink whose target does not exist.
+func (e Entry) IsBroken() bool {
+	return e.Type == TypeSymlink && e.TargetType == TypeUnknown
+}
+
+// StatEntry builds an Entry for path without following a final symlink.
+// display is stored as the Entry's Path so callers can keep relative paths.
+func StatEntry(display, path string) (Entry, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return Entry{Path: display}, err
+	}
+	return entryFromInfo(display, path, info), nil
+}
+
+func entryFromInfo(display, path string, info fs.FileInfo) Entry {
+	e := Entry{
+		Path:    display,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		e.Type = TypeSymlink
+		e.Target, _ = os.Readlink(path)
+		if target, err := os.Stat(path); err == nil {
+			e.TargetType = TypeFile
+			if target.IsDir() {
+				e.TargetType = TypeDir
+			}
+		}
+	case info.IsDir():
+		e.Type = TypeDir
+	default:
+		e.Type = TypeFile
+	}
+	return e
+}
+
+// EntryPaths returns the paths of the given entries, preserving order.
+func EntryPaths(entries []Entry) []string {
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		paths[i] = e.Path
+	}
+	return paths
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ProjectMarkers are the files and directories whose presence makes a
+// directory a project root.
+var ProjectMarkers = []string{
+	".git", ".hg", ".svn",
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py",
+	"Gemfile", "pom.xml", "build.gradle", "composer.json", "mix.exs", "CMakeLists.txt",
+}
+
+// IsProjectMarker reports whether a file or directory name marks a project root.
+func IsProjectMarker(name string) bool {
+	return slices.Contains(ProjectMarkers, name)
+}
+
+// IsProjectRoot reports whether dir contains a project marker.
+func IsProjectRoot(dir string) bool {
+	for _, marker := range ProjectMarkers {
+		if _, err := os.Lstat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectRoots returns the entries marked as project roots, joined onto root.
+func ProjectRoots(root string, entries []Entry) []string {
+	var roots []string
+	for _, e := range entries {
+		if e.IsProject {
+			roots = append(roots, filepath.Join(root, e.Path))
+		}
+	}
+	return roots
+}
@@ -1,6 +1,8 @@
 package search
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -77,3 +79,137 @@ func TestGapSearch(t *testing.T) {
 		})
 	}
 }
+
+func TestWalkEntryTypes(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "file.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("empty", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing", filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Walk(root)
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	byPath := make(map[string]Entry)
+	for _, e := range entries {
+		byPath[e.Path] = e
+	}
+
+	if e := byPath["empty"]; e.Type != TypeDir || !e.IsDir() {
+		t.Errorf("expected empty to be a directory, got %+v", e)
+	}
+	if e := byPath["file.txt"]; e.Type != TypeFile || e.Size != 5 {
+		t.Errorf("expected file.txt to be a 5 byte file, got %+v", e)
+	}
+	if e := byPath["link"]; e.Type != TypeSymlink || e.Target != "empty" || !e.IsDir() || e.IsBroken() {
+		t.Errorf("expected link to be a symlink to a directory, got %+v", e)
+	}
+	if e := byPath["dangling"]; !e.IsBroken() {
+		t.Errorf("expected dangling to be a broken symlink, got %+v", e)
+	}
+}
+
+func TestCompleteTags(t *testing.T) {
+	tags := []string{"work", "personal", "workshop", "notes", "wiki"}
+
+	got := CompleteTags(tags, "wo")
+	if len(got) < 2 || got[0] != "work" || got[1] != "workshop" {
+		t.Errorf("expected prefix matches first, got %v", got)
+	}
+	if got := CompleteTags(tags, "nts"); len(got) != 1 || got[0] != "notes" {
+		t.Errorf("expected fuzzy match notes, got %v", got)
+	}
+	if got := CompleteTags(tags, "xyz"); len(got) != 0 {
+		t.Errorf("expected no matches, got %v", got)
+	}
+	if got := CompleteTags(tags, ""); len(got) != len(tags) {
+		t.Errorf("expected every tag for an empty prefix, got %v", got)
+	}
+}
+
+func TestWalkRoots(t *testing.T) {
+	work := t.TempDir()
+	api := filepath.Join(work, "svc", "api")
+	if err := os.MkdirAll(api, 0755); err != nil {
+		t.Fatal(err)
+	}
+	handler := filepath.Join(api, "handler.go")
+	if err := os.WriteFile(handler, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(notes, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// api is nested in work, so its contents must not be listed twice
+	entries := WalkRoots([]string{work, api, notes})
+	got := EntryPaths(entries)
+	want := []string{work, filepath.Join(work, "svc"), api, handler, notes}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+
+	results := FuzzyHierarchical(got, "api handler")
+	if len(results) == 0 || results[0].Path != handler {
+		t.Errorf("expected %s to match \"api handler\", got %v", handler, results)
+	}
+}
+
+func TestWalkDetectsProjects(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"api/.git", "web", "docs", "gen"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A gitignored marker doesn't make its directory a project
+	files := map[string]string{"web/package.json": "", "gen/go.mod": "", ".gitignore": "gen/go.mod\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ProjectRoots(root, entries)
+	want := []string{filepath.Join(root, "api"), filepath.Join(root, "web")}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if !IsProjectRoot(filepath.Join(root, "web")) || IsProjectRoot(filepath.Join(root, "docs")) {
+		t.Errorf("expected web to be a project root and docs not")
+	}
+}
+
+func TestBoost(t *testing.T) {
+	results := []Result{{Path: "a", Score: 20}, {Path: "b", Score: 15}, {Path: "c", Score: 15}}
+	Boost(results, func(path string) int {
+		if path == "c" {
+			return 10
+		}
+		return 0
+	})
+	got := []string{results[0].Path, results[1].Path, results[2].Path}
+	if got[0] != "c" || got[1] != "a" || got[2] != "b" {
+		t.Errorf("expected [c a b], got %v", got)
+	}
+}
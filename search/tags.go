@@ -0,0 +1,32 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// CompleteTags returns the tags matching a partially typed name: tags that
+// start with prefix first (alphabetically), then fuzzy matches by score.
+// An empty prefix matches every tag.
+func CompleteTags(tags []string, prefix string) []string {
+	var completions []string
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		if strings.HasPrefix(strings.ToLower(t), strings.ToLower(prefix)) {
+			completions = append(completions, t)
+			seen[t] = true
+		}
+	}
+	sort.Strings(completions)
+	if prefix == "" {
+		return completions
+	}
+	for _, match := range fuzzy.Find(prefix, tags) {
+		if !seen[match.Str] {
+			completions = append(completions, match.Str)
+		}
+	}
+	return completions
+}
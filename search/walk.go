@@ -9,11 +9,14 @@ import (
 	"github.com/monochromegane/go-gitignore"
 )
 
-// Walk traverses the file tree rooted at root and returns a list of files.
+// Walk traverses the file tree rooted at root and returns typed entries
+// with paths relative to root. Directories holding a project marker
+// (see ProjectMarkers) have IsProject set.
 // It respects .gitignore if found in the root directory.
-func Walk(root string) ([]string, error) {
-	var paths []string
+func Walk(root string) ([]Entry, error) {
+	var entries []Entry
 	var ignoreMatcher gitignore.IgnoreMatcher
+	projects := make(map[string]bool) // Relative dirs containing a marker
 
 	// Check for .gitignore in root
 	gitignorePath := filepath.Join(root, ".gitignore")
@@ -34,10 +37,13 @@ func Walk(root string) ([]string, error) {
 		if relPath == "." {
 			return nil
 		}
-
 		// Default ignores
 		if d.IsDir() {
 		if strings.HasPrefix(d.Name(), ".") && d.Name() != "." {
+				// VCS directories still mark their parent as a project
+				if IsProjectMarker(d.Name()) {
+					projects[filepath.Dir(relPath)] = true
+				}
 				return filepath.SkipDir // Skip hidden directories
 			}
 			if d.Name() == "node_modules" || d.Name() == "vendor" {
@@ -55,6 +61,10 @@ func Walk(root string) ([]string, error) {
 			}
 		}
 
+		if IsProjectMarker(d.Name()) {
+			projects[filepath.Dir(relPath)] = true
+		}
+
 		// Add files only (unless we want dirs too? Spec implies navigating to files, but also "Enter on Dir drills down")
 		// The list should probably contain both?
 		// "The search engine must not just find the target; it must understand hierarchy."
@@ -67,9 +77,47 @@ func Walk(root string) ([]string, error) {
 		// "Selects file... Enter (on Dir)..." matches implies Dirs are in the list.
 		// Let's add everything.
 		
-		paths = append(paths, relPath)
+		info, err := d.Info()
+		if err != nil {
+			entries = append(entries, Entry{Path: relPath})
+			return nil
+		}
+		entries = append(entries, entryFromInfo(relPath, path, info))
 		return nil
 	})
 
-	return paths, err
+	for i := range entries {
+		if projects[entries[i].Path] && entries[i].Type == TypeDir {
+			entries[i].IsProject = true
+		}
+	}
+	return entries, err
+}
+
+// WalkRoots returns every root followed by the contents of the roots that
+// are directories (see Walk), all with absolute paths. Paths reachable from
+// more than one root, such as nested roots, are listed once.
+func WalkRoots(roots []string) []Entry {
+	var entries []Entry
+	seen := make(map[string]bool)
+	add := func(e Entry) {
+		if !seen[e.Path] {
+			seen[e.Path] = true
+			entries = append(entries, e)
+		}
+	}
+
+	for _, root := range roots {
+		e, err := StatEntry(root, root)
+		add(e)
+		if err != nil || !e.IsDir() {
+			continue
+		}
+		children, _ := Walk(root)
+		for _, child := range children {
+			child.Path = filepath.Join(root, child.Path)
+			add(child)
+		}
+	}
+	return entries
 }
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SetAlias names path so it can be opened by name; an existing alias is repointed.
+func SetAlias(db *sql.DB, name, path string) error {
+	if name == "" {
+		return fmt.Errorf("alias name is empty")
+	}
+	_, err := db.Exec(`
+		INSERT INTO aliases (name, path) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET path = excluded.path
+	`, name, path)
+	if err != nil {
+		return fmt.Errorf("failed to set alias: %w", err)
+	}
+	return nil
+}
+
+// GetAlias returns the path named name, or "" if there is no such alias.
+func GetAlias(db *sql.DB, name string) (string, error) {
+	var path string
+	err := db.QueryRow(`SELECT path FROM aliases WHERE name = ?`, name).Scan(&path)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get alias: %w", err)
+	}
+	return path, nil
+}
+
+// GetAliases returns every alias name and its path.
+func GetAliases(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query(`SELECT name, path FROM aliases`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make(map[string]string)
+	for rows.Next() {
+		var name, path string
+		if err := rows.Scan(&name, &path); err != nil {
+			return nil, err
+		}
+		aliases[name] = path
+	}
+	return aliases, nil
+}
+
+// RemoveAlias deletes an alias. It reports whether the alias existed.
+func RemoveAlias(db *sql.DB, name string) (bool, error) {
+	res, err := db.Exec(`DELETE FROM aliases WHERE name = ?`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove alias: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
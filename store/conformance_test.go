@@ -0,0 +1,473 @@
+package store
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStoreConformance runs the same behavioral checks against any Store,
+// so MemStore stays a faithful stand-in for SQLiteStore. advance moves the
+// store's clock forward by at least a second.
+func testStoreConformance(t *testing.T, s Store, advance func()) {
+	t.Run("Tags", func(t *testing.T) {
+		if err := s.AddPathToTag("work", "/b"); err != nil {
+			t.Fatal(err)
+		}
+		_ = s.AddPathToTag("work", "/a")
+		_ = s.AddPathToTag("work", "/a") // duplicate is ignored
+		_ = s.AddPathToTag("go", "/a")
+
+		paths, _ := s.GetPathsForTag("work")
+		if len(paths) != 2 || paths[0] != "/a" || paths[1] != "/b" {
+			t.Errorf("expected [/a /b], got %v", paths)
+		}
+		tags, _ := s.GetTagsForPath("/a")
+		if len(tags) != 2 || tags[0] != "go" || tags[1] != "work" {
+			t.Errorf("expected [go work], got %v", tags)
+		}
+		all, _ := s.GetAllTags()
+		if len(all) != 2 {
+			t.Errorf("expected 2 tags, got %v", all)
+		}
+		tagged, _ := s.GetAllTaggedPaths()
+		if len(tagged) != 2 {
+			t.Errorf("expected 2 tagged paths, got %v", tagged)
+		}
+
+		if err := s.RemovePathFromTag("go", "/a"); err != nil {
+			t.Fatal(err)
+		}
+		byPath, _ := s.GetTagsByPath()
+		if len(byPath["/a"]) != 1 || byPath["/a"][0] != "work" {
+			t.Errorf("expected /a -> [work], got %v", byPath["/a"])
+		}
+		all, _ = s.GetAllTags()
+		if len(all) != 1 {
+			t.Errorf("expected empty tag to disappear, got %v", all)
+		}
+	})
+
+	t.Run("TagQuery", func(t *testing.T) {
+		_ = s.AddPathToTag("q-work", "/q/api")
+		_ = s.AddPathToTag("q-go", "/q/api")
+		_ = s.AddPathToTag("q-work", "/q/web")
+		_ = s.AddPathToTag("q-personal", "/q/blog")
+		_ = s.AddPathToTag("q-go", "/q/blog")
+		_ = s.AddPathToTag("q-archive", "/q/blog")
+
+		cases := []struct {
+			input string
+			want  []string
+		}{
+			{"@q-work @q-go ", []string{"/q/api"}},
+			{"@q-work,@q-personal ", []string{"/q/api", "/q/blog", "/q/web"}},
+			{"@q-go -@q-archive ", []string{"/q/api"}},
+			{"@q-missing ", nil},
+		}
+		for _, c := range cases {
+			q, _ := ParseTagQuery(c.input)
+			got, err := s.GetPathsForTagQuery(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, " ") != strings.Join(c.want, " ") {
+				t.Errorf("%q: expected %v, got %v", c.input, c.want, got)
+			}
+		}
+		for _, name := range []string{"q-work", "q-go", "q-personal", "q-archive"} {
+			_ = s.DeleteTag(name)
+		}
+	})
+
+	t.Run("TagDefs", func(t *testing.T) {
+		if def, _ := s.GetTagDef("notes"); def.Name != "notes" || !def.IsZero() {
+			t.Errorf("expected empty definition for undefined tag, got %+v", def)
+		}
+		want := TagDef{Name: "notes", Description: "Notes", Color: "42", Icon: "N", Action: "editor"}
+		if err := s.SetTagDef(want); err != nil {
+			t.Fatal(err)
+		}
+		if def, _ := s.GetTagDef("notes"); def != want {
+			t.Errorf("expected %+v, got %+v", want, def)
+		}
+		if defs, _ := s.GetTagDefs(); len(defs) != 1 || defs["notes"] != want {
+			t.Errorf("expected only notes, got %v", defs)
+		}
+		_ = s.SetTagDef(TagDef{Name: "notes"})
+		if defs, _ := s.GetTagDefs(); len(defs) != 0 {
+			t.Errorf("expected empty definition to be deleted, got %v", defs)
+		}
+	})
+
+	t.Run("RenameDeleteTag", func(t *testing.T) {
+		_ = s.AddPathToTag("old", "/x")
+		_ = s.AddPathToTag("old", "/y")
+		_ = s.AddPathToTag("new", "/y")
+		_ = s.AddPathToTag("new", "/z")
+		_ = s.SetTagDef(TagDef{Name: "old", Color: "1"})
+
+		if counts, _ := s.GetTagCounts(); counts["old"] != 2 || counts["new"] != 2 {
+			t.Errorf("expected 2 paths in old and new, got %v", counts)
+		}
+		if err := s.RenameTag("old", "new"); err != nil {
+			t.Fatal(err)
+		}
+		if paths, _ := s.GetPathsForTag("new"); len(paths) != 3 {
+			t.Errorf("expected merged tag with 3 paths, got %v", paths)
+		}
+		if paths, _ := s.GetPathsForTag("old"); len(paths) != 0 {
+			t.Errorf("expected old tag to be gone, got %v", paths)
+		}
+		if def, _ := s.GetTagDef("new"); def.Color != "1" {
+			t.Errorf("expected definition to move with the rename, got %+v", def)
+		}
+		if err := s.RenameTag("new", ""); err == nil {
+			t.Errorf("expected rename to an empty name to fail")
+		}
+
+		if err := s.DeleteTag("new"); err != nil {
+			t.Fatal(err)
+		}
+		if counts, _ := s.GetTagCounts(); counts["new"] != 0 {
+			t.Errorf("expected deleted tag to have no paths, got %v", counts)
+		}
+		if def, _ := s.GetTagDef("new"); !def.IsZero() {
+			t.Errorf("expected deleted tag's definition to be gone, got %+v", def)
+		}
+	})
+
+	t.Run("History", func(t *testing.T) {
+		_ = s.UpdateFrecency("/old")
+		advance()
+		_ = s.UpdateFrecency("/new")
+		_ = s.UpdateFrecency("/new")
+
+		history, _ := s.GetHistory()
+		if len(history) != 2 || history[0].Path != "/new" || history[0].Frequency != 2 {
+			t.Errorf("expected /new (2 visits) first, got %v", history)
+		}
+		recent, _ := s.GetRecentHistory(1)
+		if len(recent) != 1 || recent[0].Path != "/new" {
+			t.Errorf("expected only /new, got %v", recent)
+		}
+
+		// /old was visited over a second before /new
+		n, err := s.PruneHistory(history[0].LastVisited)
+		if err != nil || n != 1 {
+			t.Errorf("expected PruneHistory to remove /old, removed %d (%v)", n, err)
+		}
+
+		if ok, _ := s.RemoveHistory("/missing"); ok {
+			t.Errorf("expected RemoveHistory of unknown path to report false")
+		}
+		if ok, _ := s.RemoveHistory("/new"); !ok {
+			t.Errorf("expected RemoveHistory to report true")
+		}
+		if history, _ := s.GetHistory(); len(history) != 0 {
+			t.Errorf("expected empty history, got %v", history)
+		}
+	})
+
+	t.Run("AgeHistory", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			_ = s.UpdateFrecency("/hot")
+		}
+		_ = s.UpdateFrecency("/cold")
+
+		if n, _ := s.AgeHistory(100); n != 0 {
+			t.Errorf("expected no aging under the limit, removed %d", n)
+		}
+		// total 11 > 5: factor 0.9*5/11 -> /hot 4, /cold 0 (removed)
+		n, err := s.AgeHistory(5)
+		if err != nil || n != 1 {
+			t.Errorf("expected one entry aged out, got %d (%v)", n, err)
+		}
+		history, _ := s.GetHistory()
+		if len(history) != 1 || history[0].Path != "/hot" || history[0].Frequency != 4 {
+			t.Errorf("expected /hot with frequency 4, got %v", history)
+		}
+	})
+
+	t.Run("MergeHistory", func(t *testing.T) {
+		old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		_ = s.MergeHistory([]HistoryItem{{Path: "/m", Frequency: 3, LastVisited: newer}})
+		if err := s.MergeHistory([]HistoryItem{{Path: "/m", Frequency: 2, LastVisited: old}}); err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, h := range mustHistory(t, s) {
+			if h.Path == "/m" {
+				found = true
+				if h.Frequency != 3 || !h.LastVisited.Equal(newer) {
+					t.Errorf("expected /m with 3 visits last on %v, got %+v", newer, h)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected /m in history")
+		}
+	})
+
+	t.Run("ContextHistory", func(t *testing.T) {
+		_ = s.UpdateContextFrecency("/work/svc", "/work/svc/README.md")
+		_ = s.UpdateContextFrecency("/work/svc", "/work/svc/README.md")
+		_ = s.UpdateContextFrecency("/home", "/etc/hosts")
+		_ = s.UpdateFrecency("/work/svc/README.md")
+
+		visits, _ := s.GetContextHistory("/work/svc")
+		if len(visits) != 1 || visits[0].Path != "/work/svc/README.md" || visits[0].Frequency != 2 {
+			t.Errorf("expected README.md with 2 visits from /work/svc, got %v", visits)
+		}
+		if visits, _ := s.GetContextHistory("/unseen"); len(visits) != 0 {
+			t.Errorf("expected no visits from an unseen context, got %v", visits)
+		}
+
+		// Forgetting a path forgets it in every context
+		_, _ = s.RemoveHistory("/work/svc/README.md")
+		if visits, _ := s.GetContextHistory("/work/svc"); len(visits) != 0 {
+			t.Errorf("expected RemoveHistory to clear context visits, got %v", visits)
+		}
+		if visits, _ := s.GetContextHistory("/home"); len(visits) != 1 {
+			t.Errorf("expected other paths to stay, got %v", visits)
+		}
+	})
+
+	t.Run("QueryPicks", func(t *testing.T) {
+		_ = s.RecordQueryPick("cfg", "/deploy/config/prod.yaml")
+		_ = s.RecordQueryPick(" CFG ", "/deploy/config/prod.yaml")
+		_ = s.RecordQueryPick("cfgx", "/x/cfgx")
+		_ = s.RecordQueryPick("cfgx", "/x/cfgx")
+		_ = s.RecordQueryPick("cfgx", "/x/cfgx")
+		_ = s.RecordQueryPick("", "/ignored")
+
+		picks, err := s.GetQueryPicks("cfg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The exact query wins over a more often picked longer one
+		if len(picks) != 2 || picks[0].Path != "/deploy/config/prod.yaml" || picks[0].Count != 2 || picks[1].Path != "/x/cfgx" {
+			t.Errorf("expected prod.yaml (2) then cfgx, got %+v", picks)
+		}
+		if picks[0].LastPicked.IsZero() {
+			t.Errorf("expected a last pick time")
+		}
+		if picks, _ := s.GetQueryPicks("c"); len(picks) != 2 || picks[0].Path != "/x/cfgx" {
+			t.Errorf("expected a prefix to rank by count, got %+v", picks)
+		}
+		if picks, _ := s.GetQueryPicks("cfgxy"); len(picks) != 0 {
+			t.Errorf("expected no picks for a longer query, got %+v", picks)
+		}
+
+		_, _ = s.RemoveHistory("/x/cfgx")
+		if picks, _ := s.GetQueryPicks("cfg"); len(picks) != 1 {
+			t.Errorf("expected RemoveHistory to forget the picks, got %+v", picks)
+		}
+	})
+
+	t.Run("Projects", func(t *testing.T) {
+		if err := s.AddProjects([]string{"/p/web", "/p/api"}); err != nil {
+			t.Fatal(err)
+		}
+		_ = s.AddProjects([]string{"/p/api"}) // known projects are ignored
+		projects, _ := s.GetProjects()
+		if strings.Join(projects, " ") != "/p/api /p/web" {
+			t.Errorf("expected [/p/api /p/web], got %v", projects)
+		}
+		if ok, _ := s.RemoveProject("/p/web"); !ok {
+			t.Errorf("expected RemoveProject to report true")
+		}
+		if ok, _ := s.RemoveProject("/p/web"); ok {
+			t.Errorf("expected second RemoveProject to report false")
+		}
+	})
+
+	t.Run("Pins", func(t *testing.T) {
+		_ = s.AddPin("/b")
+		_ = s.AddPin("/a")
+		_ = s.AddPin("/b") // pinned paths keep their place
+		if pins, _ := s.GetPins(); strings.Join(pins, " ") != "/b /a" {
+			t.Errorf("expected [/b /a], got %v", pins)
+		}
+		if err := s.SetPins([]string{"/a", "/c", "/b"}); err != nil {
+			t.Fatal(err)
+		}
+		if ok, _ := s.RemovePin("/c"); !ok {
+			t.Errorf("expected RemovePin to report true")
+		}
+		if ok, _ := s.RemovePin("/c"); ok {
+			t.Errorf("expected second RemovePin to report false")
+		}
+		_ = s.AddPin("/d")
+		if pins, _ := s.GetPins(); strings.Join(pins, " ") != "/a /b /d" {
+			t.Errorf("expected [/a /b /d], got %v", pins)
+		}
+	})
+
+	t.Run("Aliases", func(t *testing.T) {
+		_ = s.SetAlias("dots", "/home/me/old-dots")
+		if err := s.SetAlias("dots", "/home/me/.dotfiles"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetAlias("", "/x"); err == nil {
+			t.Errorf("expected an empty alias name to be rejected")
+		}
+		if p, _ := s.GetAlias("dots"); p != "/home/me/.dotfiles" {
+			t.Errorf("expected dots to be repointed, got %q", p)
+		}
+		if p, _ := s.GetAlias("missing"); p != "" {
+			t.Errorf("expected no path for an unknown alias, got %q", p)
+		}
+		if aliases, _ := s.GetAliases(); len(aliases) != 1 {
+			t.Errorf("expected one alias, got %v", aliases)
+		}
+		if ok, _ := s.RemoveAlias("dots"); !ok {
+			t.Errorf("expected RemoveAlias to report true")
+		}
+		if ok, _ := s.RemoveAlias("dots"); ok {
+			t.Errorf("expected second RemoveAlias to report false")
+		}
+	})
+
+	t.Run("Settings", func(t *testing.T) {
+		if v, _ := s.GetSetting("missing"); v != "" {
+			t.Errorf("expected empty value for missing key, got %q", v)
+		}
+		_ = s.SetSetting("k", "v1")
+		_ = s.SetSetting("k", "v2")
+		if v, _ := s.GetSetting("k"); v != "v2" {
+			t.Errorf("expected v2, got %q", v)
+		}
+	})
+}
+
+// testStateRoundTrip exports src, round-trips the snapshot through JSON and
+// imports it into dst in merge and replace modes.
+func testStateRoundTrip(t *testing.T, src, dst Store) {
+	visited := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	_ = src.AddPathToTag("services", "/srv/api")
+	_ = src.MergeHistory([]HistoryItem{{Path: "/srv/api", Frequency: 4, LastVisited: visited}})
+	_ = src.UpdateContextFrecency("/srv", "/srv/api")
+	_ = src.RecordQueryPick("api", "/srv/api")
+	_ = src.SetSetting("default_action", "editor")
+	_ = src.SetTagDef(TagDef{Name: "services", Color: "33"})
+	_ = src.AddPin("/srv/api")
+	_ = src.SetAlias("api", "/srv/api")
+
+	state, err := src.ExportState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded State
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = dst.AddPathToTag("local", "/home/me")
+	_ = dst.AddPin("/home/me")
+	_ = dst.SetSetting("default_action", "terminal")
+	_ = dst.MergeHistory([]HistoryItem{{Path: "/srv/api", Frequency: 6, LastVisited: visited.Add(-time.Hour)}})
+
+	if err := dst.ImportState(decoded, false); err != nil {
+		t.Fatalf("merge import failed: %v", err)
+	}
+	if tags, _ := dst.GetAllTags(); len(tags) != 2 {
+		t.Errorf("expected merge to keep local tags, got %v", tags)
+	}
+	if pins, _ := dst.GetPins(); len(pins) != 2 || pins[1] != "/srv/api" {
+		t.Errorf("expected imported pins after local ones, got %v", pins)
+	}
+	if p, _ := dst.GetAlias("api"); p != "/srv/api" {
+		t.Errorf("expected imported alias, got %q", p)
+	}
+	if def, _ := dst.GetTagDef("services"); def.Color != "33" {
+		t.Errorf("expected imported tag definition, got %+v", def)
+	}
+	if v, _ := dst.GetSetting("default_action"); v != "editor" {
+		t.Errorf("expected imported setting to win, got %q", v)
+	}
+	history := mustHistory(t, dst)
+	if len(history) != 1 || history[0].Frequency != 6 || !history[0].LastVisited.Equal(visited) {
+		t.Errorf("expected merged history (6 visits, %v), got %v", visited, history)
+	}
+
+	// Importing the same snapshot again changes nothing
+	before, _ := dst.ExportState()
+	if err := dst.ImportState(decoded, false); err != nil {
+		t.Fatalf("second merge import failed: %v", err)
+	}
+	if after, _ := dst.ExportState(); !reflect.DeepEqual(before, after) {
+		t.Errorf("expected a repeated import to be a no-op:\nbefore %+v\nafter  %+v", before, after)
+	}
+
+	if err := dst.ImportState(decoded, true); err != nil {
+		t.Fatalf("replace import failed: %v", err)
+	}
+	if tags, _ := dst.GetAllTags(); len(tags) != 1 || tags[0] != "services" {
+		t.Errorf("expected replace to drop local tags, got %v", tags)
+	}
+	if pins, _ := dst.GetPins(); len(pins) != 1 || pins[0] != "/srv/api" {
+		t.Errorf("expected replace to drop local pins, got %v", pins)
+	}
+	history = mustHistory(t, dst)
+	if len(history) != 1 || history[0].Frequency != 4 {
+		t.Errorf("expected replaced history (4 visits), got %v", history)
+	}
+	if visits, _ := dst.GetContextHistory("/srv"); len(visits) != 1 || visits[0].Path != "/srv/api" {
+		t.Errorf("expected replace to keep the exported context history, got %v", visits)
+	}
+	if picks, _ := dst.GetQueryPicks("api"); len(picks) != 1 || picks[0].Path != "/srv/api" || picks[0].Count != 1 {
+		t.Errorf("expected replace to keep the exported query picks, got %v", picks)
+	}
+
+	if err := dst.ImportState(State{Version: StateVersion + 1}, false); err == nil {
+		t.Errorf("expected newer state version to be rejected")
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	open := func() Store {
+		s, err := Open(MemoryDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+	t.Run("SQLite", func(t *testing.T) { testStateRoundTrip(t, open(), open()) })
+	t.Run("Mem", func(t *testing.T) { testStateRoundTrip(t, NewMemStore(), NewMemStore()) })
+	t.Run("SQLiteToMem", func(t *testing.T) { testStateRoundTrip(t, open(), NewMemStore()) })
+}
+
+func TestSQLiteStoreConformance(t *testing.T) {
+	s, err := Open(MemoryDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	// CURRENT_TIMESTAMP has second resolution and can't be injected
+	testStoreConformance(t, s, func() { time.Sleep(1100 * time.Millisecond) })
+}
+
+func TestMemStoreConformance(t *testing.T) {
+	s := NewMemStore()
+	now := time.Now()
+	s.Now = func() time.Time { return now }
+	testStoreConformance(t, s, func() { now = now.Add(time.Second) })
+}
+
+func mustHistory(t *testing.T, s Store) []HistoryItem {
+	t.Helper()
+	history, err := s.GetHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return history
+}
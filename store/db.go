@@ -9,18 +9,49 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// InitDB initializes the SQLite database at the given path.
-// It creates the necessary tables if they don't exist.
+// MemoryDB is the database path that keeps everything in memory for the
+// lifetime of the process (tests and ephemeral sessions).
+const MemoryDB = ":memory:"
+
+// ResolveDBPath picks the database location. In order of precedence:
+// the explicit override (e.g. --db), $NAVI_DATA_DIR/navi.db,
+// $XDG_DATA_HOME/navi/navi.db and ~/.local/share/navi/navi.db.
+func ResolveDBPath(override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	if dir := os.Getenv("NAVI_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, "navi.db"), nil
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "navi", "navi.db"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "navi", "navi.db"), nil
+}
+
+// InitDB initializes the SQLite database at the given path, or in memory
+// if dbPath is MemoryDB.
+// It brings the schema up to date by running any pending migrations.
 func InitDB(dbPath string) (*sql.DB, error) {
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
-		return nil, fmt.Errorf("failed to create db directory: %w", err)
+	if dbPath != MemoryDB {
+		// Ensure directory exists
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create db directory: %w", err)
+		}
 	}
 
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	if dbPath == MemoryDB {
+		// Every connection to :memory: is a separate database, so pin the pool to one.
+		db.SetMaxOpenConns(1)
+	}
 
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
@@ -31,39 +62,10 @@ func InitDB(dbPath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
 	}
 
-	if err := createTables(db); err != nil {
+	if err := migrate(db, dbPath, migrations); err != nil {
 		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
-
-func createTables(db *sql.DB) error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS tags (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			path TEXT NOT NULL,
-			UNIQUE(name, path)
-		);`,
-		`CREATE TABLE IF NOT EXISTS settings (
-			key TEXT PRIMARY KEY,
-			value TEXT NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			path TEXT NOT NULL UNIQUE,
-			frequency INTEGER DEFAULT 1,
-			last_visited TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		);`,
-	}
-
-	for _, query := range queries {
-		if _, err := db.Exec(query); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
-		}
-	}
-
-	return nil
-}
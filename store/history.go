@@ -6,10 +6,29 @@ import (
 	"time"
 )
 
+// sqliteTime is the layout of CURRENT_TIMESTAMP values (always UTC).
+const sqliteTime = "2006-01-02 15:04:05"
+
 type HistoryItem struct {
-	Path        string
-	Frequency   int
-	LastVisited time.Time
+	Path        string    `json:"path"`
+	Frequency   int       `json:"frequency"`
+	LastVisited time.Time `json:"last_visited"`
+}
+
+// Frecency scores the item zoxide-style: the visit count weighted by how
+// recently the path was last visited.
+func (h HistoryItem) Frecency(now time.Time) float64 {
+	age := now.Sub(h.LastVisited)
+	switch {
+	case age < time.Hour:
+		return float64(h.Frequency) * 4
+	case age < 24*time.Hour:
+		return float64(h.Frequency) * 2
+	case age < 7*24*time.Hour:
+		return float64(h.Frequency) / 2
+	default:
+		return float64(h.Frequency) / 4
+	}
 }
 
 // UpdateFrecency updates the frequency and last_visited timestamp for a path.
@@ -73,3 +92,141 @@ func GetRecentHistory(db *sql.DB, limit int) ([]HistoryItem, error) {
 	}
 	return items, nil
 }
+
+// RemoveHistory deletes a path from history, in every context, and forgets
+// the queries it was picked for. It reports whether the path was present.
+func RemoveHistory(db *sql.DB, path string) (bool, error) {
+	for _, table := range []string{"context_history", "query_picks"} {
+		if _, err := db.Exec(`DELETE FROM `+table+` WHERE path = ?`, path); err != nil {
+			return false, fmt.Errorf("failed to remove history: %w", err)
+		}
+	}
+	res, err := db.Exec(`DELETE FROM history WHERE path = ?`, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove history: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// PruneHistory deletes entries last visited before cutoff and returns how
+// many were removed from the global history.
+func PruneHistory(db *sql.DB, cutoff time.Time) (int, error) {
+	if _, err := db.Exec(`DELETE FROM context_history WHERE last_visited < ?`, cutoff.UTC().Format(sqliteTime)); err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	res, err := db.Exec(`DELETE FROM history WHERE last_visited < ?`, cutoff.UTC().Format(sqliteTime))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// AgeHistory applies zoxide-style aging: once the total frequency exceeds
+// maxTotal, every frequency is scaled down so the total drops to 90% of
+// maxTotal, and entries that fall below 1 are removed. Context history is
+// scaled by the same factor. It returns how many global entries were removed.
+func AgeHistory(db *sql.DB, maxTotal int) (int, error) {
+	if maxTotal <= 0 {
+		return 0, nil
+	}
+	var total int
+	if err := db.QueryRow(`SELECT COALESCE(SUM(frequency), 0) FROM history`).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to sum history: %w", err)
+	}
+	if total <= maxTotal {
+		return 0, nil
+	}
+
+	factor := 0.9 * float64(maxTotal) / float64(total)
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to age history: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE history SET frequency = CAST(frequency * ? AS INTEGER)`, factor); err != nil {
+		return 0, fmt.Errorf("failed to age history: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE context_history SET frequency = CAST(frequency * ? AS INTEGER)`, factor); err != nil {
+		return 0, fmt.Errorf("failed to age history: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM context_history WHERE frequency < 1`); err != nil {
+		return 0, fmt.Errorf("failed to age history: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM history WHERE frequency < 1`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to age history: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to age history: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// MergeHistory upserts items into history in one transaction. The higher
+// frequency and the later last_visited win, so merging the same items twice
+// leaves history unchanged.
+func MergeHistory(db *sql.DB, items []HistoryItem) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to merge history: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO history (path, frequency, last_visited)
+		VALUES (?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			frequency = MAX(frequency, excluded.frequency),
+			last_visited = MAX(last_visited, excluded.last_visited)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to merge history: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, item := range items {
+		if _, err := stmt.Exec(item.Path, item.Frequency, item.LastVisited.UTC().Format(sqliteTime)); err != nil {
+			return fmt.Errorf("failed to merge history for %q: %w", item.Path, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// UpdateContextFrecency records a visit to path made from context (the
+// project or directory navi was started in).
+func UpdateContextFrecency(db *sql.DB, context, path string) error {
+	query := `
+		INSERT INTO context_history (context, path, frequency, last_visited)
+		VALUES (?, ?, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT(context, path) DO UPDATE SET
+			frequency = frequency + 1,
+			last_visited = CURRENT_TIMESTAMP
+	`
+	if _, err := db.Exec(query, context, path); err != nil {
+		return fmt.Errorf("failed to update context frecency: %w", err)
+	}
+	return nil
+}
+
+// GetContextHistory returns the paths visited from context, most recent first.
+func GetContextHistory(db *sql.DB, context string) ([]HistoryItem, error) {
+	query := `SELECT path, frequency, last_visited FROM context_history WHERE context = ? ORDER BY last_visited DESC, path`
+	rows, err := db.Query(query, context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get context history: %w", err)
+	}
+	defer rows.Close()
+
+	var items []HistoryItem
+	for rows.Next() {
+		var item HistoryItem
+		if err := rows.Scan(&item.Path, &item.Frequency, &item.LastVisited); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
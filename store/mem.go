@@ -0,0 +1,663 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemStore is an in-memory Store for tests. It mirrors the ordering and
+// upsert semantics of SQLiteStore without touching SQLite.
+type MemStore struct {
+	mu       sync.Mutex
+	tags     map[string]map[string]bool // tag -> set of paths
+	tagDefs  map[string]TagDef
+	history  map[string]HistoryItem
+	contexts map[string]map[string]HistoryItem // context -> path -> visits from it
+	picks    map[string]map[string]QueryPick   // normalized query -> path -> picks
+	projects map[string]bool
+	pins     []string
+	aliases  map[string]string
+	settings map[string]string
+
+	// Now is used for last_visited timestamps; tests may override it.
+	Now func() time.Time
+}
+
+// NewMemStore returns an empty in-memory store.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		tags:     make(map[string]map[string]bool),
+		tagDefs:  make(map[string]TagDef),
+		history:  make(map[string]HistoryItem),
+		contexts: make(map[string]map[string]HistoryItem),
+		picks:    make(map[string]map[string]QueryPick),
+		projects: make(map[string]bool),
+		aliases:  make(map[string]string),
+		settings: make(map[string]string),
+		Now:      time.Now,
+	}
+}
+
+func (s *MemStore) Close() error { return nil }
+
+func (s *MemStore) AddPathToTag(tagName, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tags[tagName] == nil {
+		s.tags[tagName] = make(map[string]bool)
+	}
+	s.tags[tagName][path] = true
+	return nil
+}
+
+func (s *MemStore) RemovePathFromTag(tagName, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tags[tagName], path)
+	if len(s.tags[tagName]) == 0 {
+		delete(s.tags, tagName)
+	}
+	return nil
+}
+
+func (s *MemStore) GetPathsForTag(tagName string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.tags[tagName]), nil
+}
+
+func (s *MemStore) GetPathsForTagQuery(q TagQuery) ([]string, error) {
+	if q.IsEmpty() {
+		return nil, nil
+	}
+	byPath, _ := s.GetTagsByPath()
+	var paths []string
+	for path, tags := range byPath {
+		if q.Match(tags) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (s *MemStore) GetAllTaggedPaths() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set := make(map[string]bool)
+	for _, paths := range s.tags {
+		for p := range paths {
+			set[p] = true
+		}
+	}
+	return sortedKeys(set), nil
+}
+
+func (s *MemStore) GetTagsForPath(path string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tags []string
+	for name, paths := range s.tags {
+		if paths[path] {
+			tags = append(tags, name)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (s *MemStore) GetAllTags() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tags []string
+	for name := range s.tags {
+		tags = append(tags, name)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (s *MemStore) GetTagsByPath() (map[string][]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byPath := make(map[string][]string)
+	for name, paths := range s.tags {
+		for p := range paths {
+			byPath[p] = append(byPath[p], name)
+		}
+	}
+	for _, tags := range byPath {
+		sort.Strings(tags)
+	}
+	return byPath, nil
+}
+
+func (s *MemStore) GetTagCounts() (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int, len(s.tags))
+	for name, paths := range s.tags {
+		counts[name] = len(paths)
+	}
+	return counts, nil
+}
+
+func (s *MemStore) RenameTag(oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("failed to rename tag: new name is empty")
+	}
+	if oldName == newName {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.tags[oldName]) > 0 && s.tags[newName] == nil {
+		s.tags[newName] = make(map[string]bool)
+	}
+	for p := range s.tags[oldName] {
+		s.tags[newName][p] = true
+	}
+	if def, ok := s.tagDefs[oldName]; ok {
+		if _, exists := s.tagDefs[newName]; !exists {
+			def.Name = newName
+			s.tagDefs[newName] = def
+		}
+	}
+	delete(s.tags, oldName)
+	delete(s.tagDefs, oldName)
+	return nil
+}
+
+func (s *MemStore) DeleteTag(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tags, name)
+	delete(s.tagDefs, name)
+	return nil
+}
+
+func (s *MemStore) GetTagDef(name string) (TagDef, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if def, ok := s.tagDefs[name]; ok {
+		return def, nil
+	}
+	return TagDef{Name: name}, nil
+}
+
+func (s *MemStore) GetTagDefs() (map[string]TagDef, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defs := make(map[string]TagDef, len(s.tagDefs))
+	for name, def := range s.tagDefs {
+		defs[name] = def
+	}
+	return defs, nil
+}
+
+func (s *MemStore) SetTagDef(def TagDef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if def.IsZero() {
+		delete(s.tagDefs, def.Name)
+		return nil
+	}
+	s.tagDefs[def.Name] = def
+	return nil
+}
+
+func (s *MemStore) UpdateFrecency(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := s.history[path]
+	item.Path = path
+	item.Frequency++
+	item.LastVisited = s.Now()
+	s.history[path] = item
+	return nil
+}
+
+func (s *MemStore) GetHistory() ([]HistoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedHistory(), nil
+}
+
+func (s *MemStore) GetRecentHistory(limit int) ([]HistoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := s.sortedHistory()
+	if limit >= 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (s *MemStore) RemoveHistory(path string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.history[path]
+	delete(s.history, path)
+	for _, visits := range s.contexts {
+		delete(visits, path)
+	}
+	for _, picks := range s.picks {
+		delete(picks, path)
+	}
+	return ok, nil
+}
+
+func (s *MemStore) PruneHistory(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for path, item := range s.history {
+		if item.LastVisited.Before(cutoff) {
+			delete(s.history, path)
+			removed++
+		}
+	}
+	for _, visits := range s.contexts {
+		for path, item := range visits {
+			if item.LastVisited.Before(cutoff) {
+				delete(visits, path)
+			}
+		}
+	}
+	return removed, nil
+}
+
+func (s *MemStore) AgeHistory(maxTotal int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if maxTotal <= 0 {
+		return 0, nil
+	}
+	total := 0
+	for _, item := range s.history {
+		total += item.Frequency
+	}
+	if total <= maxTotal {
+		return 0, nil
+	}
+	factor := 0.9 * float64(maxTotal) / float64(total)
+	removed := 0
+	for path, item := range s.history {
+		item.Frequency = int(float64(item.Frequency) * factor)
+		if item.Frequency < 1 {
+			delete(s.history, path)
+			removed++
+			continue
+		}
+		s.history[path] = item
+	}
+	for _, visits := range s.contexts {
+		for path, item := range visits {
+			item.Frequency = int(float64(item.Frequency) * factor)
+			if item.Frequency < 1 {
+				delete(visits, path)
+				continue
+			}
+			visits[path] = item
+		}
+	}
+	return removed, nil
+}
+
+func (s *MemStore) MergeHistory(items []HistoryItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mergeHistory(items)
+	return nil
+}
+
+// mergeHistory implements MergeHistory; the caller holds s.mu.
+func (s *MemStore) mergeHistory(items []HistoryItem) {
+	for _, in := range items {
+		mergeHistoryItem(s.history, in)
+	}
+}
+
+// mergeHistoryItem merges in into visits: the higher frequency and the later
+// last visit win.
+func mergeHistoryItem(visits map[string]HistoryItem, in HistoryItem) {
+	item, ok := visits[in.Path]
+	if !ok {
+		visits[in.Path] = in
+		return
+	}
+	item.Frequency = max(item.Frequency, in.Frequency)
+	if in.LastVisited.After(item.LastVisited) {
+		item.LastVisited = in.LastVisited
+	}
+	visits[in.Path] = item
+}
+
+func (s *MemStore) UpdateContextFrecency(context, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.contexts[context] == nil {
+		s.contexts[context] = make(map[string]HistoryItem)
+	}
+	item := s.contexts[context][path]
+	item.Path = path
+	item.Frequency++
+	item.LastVisited = s.Now()
+	s.contexts[context][path] = item
+	return nil
+}
+
+func (s *MemStore) GetContextHistory(context string) ([]HistoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortHistory(s.contexts[context]), nil
+}
+
+func (s *MemStore) RecordQueryPick(query, path string) error {
+	query = NormalizeQuery(query)
+	if query == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.picks[query] == nil {
+		s.picks[query] = make(map[string]QueryPick)
+	}
+	pick := s.picks[query][path]
+	pick.Path = path
+	pick.Count++
+	pick.LastPicked = s.Now().UTC().Truncate(time.Second)
+	s.picks[query][path] = pick
+	return nil
+}
+
+func (s *MemStore) GetQueryPicks(query string) ([]QueryPick, error) {
+	query = NormalizeQuery(query)
+	if query == "" {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byPath := make(map[string]QueryPick)
+	exact := make(map[string]bool)
+	for q, picks := range s.picks {
+		if !strings.HasPrefix(q, query) {
+			continue
+		}
+		for path, p := range picks {
+			sum := byPath[path]
+			sum.Path = path
+			sum.Count += p.Count
+			if p.LastPicked.After(sum.LastPicked) {
+				sum.LastPicked = p.LastPicked
+			}
+			byPath[path] = sum
+			exact[path] = exact[path] || q == query
+		}
+	}
+	result := make([]QueryPick, 0, len(byPath))
+	for _, p := range byPath {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if exact[a.Path] != exact[b.Path] {
+			return exact[a.Path]
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if !a.LastPicked.Equal(b.LastPicked) {
+			return a.LastPicked.After(b.LastPicked)
+		}
+		return a.Path < b.Path
+	})
+	return result, nil
+}
+
+// sortedHistory returns history ordered by last_visited DESC. Callers hold mu.
+func (s *MemStore) sortedHistory() []HistoryItem {
+	return sortHistory(s.history)
+}
+
+// sortHistory orders items by last_visited DESC, then path.
+func sortHistory(history map[string]HistoryItem) []HistoryItem {
+	items := make([]HistoryItem, 0, len(history))
+	for _, item := range history {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].LastVisited.Equal(items[j].LastVisited) {
+			return items[i].LastVisited.After(items[j].LastVisited)
+		}
+		return items[i].Path < items[j].Path
+	})
+	return items
+}
+
+func (s *MemStore) AddProjects(paths []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range paths {
+		s.projects[p] = true
+	}
+	return nil
+}
+
+func (s *MemStore) GetProjects() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.projects), nil
+}
+
+func (s *MemStore) RemoveProject(path string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ok := s.projects[path]
+	delete(s.projects, path)
+	return ok, nil
+}
+
+func (s *MemStore) AddPin(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.pins, path) {
+		s.pins = append(s.pins, path)
+	}
+	return nil
+}
+
+func (s *MemStore) GetPins() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.pins), nil
+}
+
+func (s *MemStore) RemovePin(path string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.Index(s.pins, path)
+	if i < 0 {
+		return false, nil
+	}
+	s.pins = slices.Delete(s.pins, i, i+1)
+	return true, nil
+}
+
+func (s *MemStore) SetPins(paths []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pins = nil
+	for _, p := range paths {
+		if !slices.Contains(s.pins, p) {
+			s.pins = append(s.pins, p)
+		}
+	}
+	return nil
+}
+
+func (s *MemStore) SetAlias(name, path string) error {
+	if name == "" {
+		return fmt.Errorf("alias name is empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aliases[name] = path
+	return nil
+}
+
+func (s *MemStore) GetAlias(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.aliases[name], nil
+}
+
+func (s *MemStore) GetAliases() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aliases := make(map[string]string, len(s.aliases))
+	for name, path := range s.aliases {
+		aliases[name] = path
+	}
+	return aliases, nil
+}
+
+func (s *MemStore) RemoveAlias(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.aliases[name]
+	delete(s.aliases, name)
+	return ok, nil
+}
+
+func (s *MemStore) GetSetting(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings[key], nil
+}
+
+func (s *MemStore) SetSetting(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings[key] = value
+	return nil
+}
+
+func (s *MemStore) ExportState() (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := State{Version: StateVersion, Settings: make(map[string]string)}
+	for _, name := range sortedKeys(boolSet(s.tags)) {
+		for _, p := range sortedKeys(s.tags[name]) {
+			state.Tags = append(state.Tags, TagEntry{Name: name, Path: p})
+		}
+	}
+	for _, name := range sortedKeys(boolSet(s.tagDefs)) {
+		state.TagDefs = append(state.TagDefs, s.tagDefs[name])
+	}
+	state.History = s.sortedHistory()
+	for _, context := range sortedKeys(boolSet(s.contexts)) {
+		for _, item := range sortHistory(s.contexts[context]) {
+			state.Contexts = append(state.Contexts, ContextEntry{Context: context, HistoryItem: item})
+		}
+	}
+	for _, query := range sortedKeys(boolSet(s.picks)) {
+		for _, path := range sortedKeys(boolSet(s.picks[query])) {
+			state.Picks = append(state.Picks, PickEntry{Query: query, QueryPick: s.picks[query][path]})
+		}
+	}
+	state.Pins = slices.Clone(s.pins)
+	if len(s.aliases) > 0 {
+		state.Aliases = make(map[string]string, len(s.aliases))
+		for name, path := range s.aliases {
+			state.Aliases[name] = path
+		}
+	}
+	for k, v := range s.settings {
+		state.Settings[k] = v
+	}
+	return state, nil
+}
+
+func (s *MemStore) ImportState(state State, replace bool) error {
+	if state.Version > StateVersion {
+		return fmt.Errorf("state version %d is newer than supported version %d", state.Version, StateVersion)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if replace {
+		s.tags = make(map[string]map[string]bool)
+		s.tagDefs = make(map[string]TagDef)
+		s.history = make(map[string]HistoryItem)
+		s.contexts = make(map[string]map[string]HistoryItem)
+		s.picks = make(map[string]map[string]QueryPick)
+		s.pins = nil
+		s.aliases = make(map[string]string)
+		s.settings = make(map[string]string)
+	}
+	for _, t := range state.Tags {
+		if s.tags[t.Name] == nil {
+			s.tags[t.Name] = make(map[string]bool)
+		}
+		s.tags[t.Name][t.Path] = true
+	}
+	for _, def := range state.TagDefs {
+		s.tagDefs[def.Name] = def
+	}
+	s.mergeHistory(state.History)
+	for _, c := range state.Contexts {
+		if s.contexts[c.Context] == nil {
+			s.contexts[c.Context] = make(map[string]HistoryItem)
+		}
+		mergeHistoryItem(s.contexts[c.Context], c.HistoryItem)
+	}
+	for _, p := range state.Picks {
+		if s.picks[p.Query] == nil {
+			s.picks[p.Query] = make(map[string]QueryPick)
+		}
+		pick, ok := s.picks[p.Query][p.Path]
+		if !ok {
+			s.picks[p.Query][p.Path] = p.QueryPick
+			continue
+		}
+		pick.Count = max(pick.Count, p.Count)
+		if p.LastPicked.After(pick.LastPicked) {
+			pick.LastPicked = p.LastPicked
+		}
+		s.picks[p.Query][p.Path] = pick
+	}
+	for _, p := range state.Pins {
+		if !slices.Contains(s.pins, p) {
+			s.pins = append(s.pins, p)
+		}
+	}
+	for name, path := range state.Aliases {
+		s.aliases[name] = path
+	}
+	for k, v := range state.Settings {
+		s.settings[k] = v
+	}
+	return nil
+}
+
+// boolSet returns the key set of a map.
+func boolSet[V any](m map[string]V) map[string]bool {
+	set := make(map[string]bool, len(m))
+	for k := range m {
+		set[k] = true
+	}
+	return set
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
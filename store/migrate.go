@@ -0,0 +1,214 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+)
+
+// migration is a single ordered schema upgrade.
+type migration struct {
+	version int
+	name    string
+	// destructive migrations drop or rewrite data, so the db file is backed up first
+	destructive bool
+	up          func(tx *sql.Tx) error
+}
+
+// migrations lists every schema upgrade in order. Append new ones at the end;
+// never edit or reorder a migration that has shipped.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial tables",
+		up: func(tx *sql.Tx) error {
+			// IF NOT EXISTS: databases created before schema_version existed
+			// already have these tables and start at version 0.
+			return execAll(tx,
+				`CREATE TABLE IF NOT EXISTS tags (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					name TEXT NOT NULL,
+					path TEXT NOT NULL,
+					UNIQUE(name, path)
+				);`,
+				`CREATE TABLE IF NOT EXISTS settings (
+					key TEXT PRIMARY KEY,
+					value TEXT NOT NULL
+				);`,
+				`CREATE TABLE IF NOT EXISTS history (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					path TEXT NOT NULL UNIQUE,
+					frequency INTEGER DEFAULT 1,
+					last_visited TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);`,
+			)
+		},
+	},
+	{
+		version: 2,
+		name:    "tag definitions",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE tag_defs (
+					name TEXT PRIMARY KEY,
+					description TEXT NOT NULL DEFAULT '',
+					color TEXT NOT NULL DEFAULT '',
+					icon TEXT NOT NULL DEFAULT '',
+					action TEXT NOT NULL DEFAULT ''
+				);`,
+			)
+		},
+	},
+	{
+		version: 3,
+		name:    "projects",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE projects (
+					path TEXT PRIMARY KEY,
+					detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);`,
+			)
+		},
+	},
+	{
+		version: 4,
+		name:    "context history",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE context_history (
+					context TEXT NOT NULL,
+					path TEXT NOT NULL,
+					frequency INTEGER DEFAULT 1,
+					last_visited TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (context, path)
+				);`,
+			)
+		},
+	},
+	{
+		version: 5,
+		name:    "query picks",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE query_picks (
+					query TEXT NOT NULL,
+					path TEXT NOT NULL,
+					count INTEGER DEFAULT 1,
+					last_picked TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (query, path)
+				);`,
+			)
+		},
+	},
+	{
+		version: 6,
+		name:    "pins",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE pins (
+					path TEXT PRIMARY KEY,
+					position INTEGER NOT NULL
+				);`,
+			)
+		},
+	},
+	{
+		version: 7,
+		name:    "aliases",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE aliases (
+					name TEXT PRIMARY KEY,
+					path TEXT NOT NULL
+				);`,
+			)
+		},
+	},
+}
+
+func execAll(tx *sql.Tx, queries ...string) error {
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the current schema version, creating the
+// schema_version table (at version 0) if it doesn't exist.
+func schemaVersion(db *sql.DB) (int, error) {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return 0, fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+	var version int
+	err := db.QueryRow(`SELECT version FROM schema_version LIMIT 1`).Scan(&version)
+	if err == sql.ErrNoRows {
+		if _, err := db.Exec(`INSERT INTO schema_version (version) VALUES (0)`); err != nil {
+			return 0, fmt.Errorf("failed to init schema_version: %w", err)
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema_version: %w", err)
+	}
+	return version, nil
+}
+
+// migrate applies every migration newer than the database's schema version.
+// Each migration runs in its own transaction together with the version bump,
+// so a failure leaves the database at the last good version.
+func migrate(db *sql.DB, dbPath string, migrations []migration) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range migrations {
+		if mig.version <= version {
+			continue
+		}
+		if mig.destructive {
+			if err := backupDB(db, dbPath, version); err != nil {
+				return fmt.Errorf("failed to back up before migration %d (%s): %w", mig.version, mig.name, err)
+			}
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", mig.version, err)
+		}
+		if err := mig.up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s) failed: %w", mig.version, mig.name, err)
+		}
+		if _, err := tx.Exec(`UPDATE schema_version SET version = ?`, mig.version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", mig.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", mig.version, err)
+		}
+		version = mig.version
+	}
+	return nil
+}
+
+// backupDB writes a consistent copy of the database next to dbPath,
+// named after the schema version it was taken at.
+// In-memory databases have nothing to back up.
+func backupDB(db *sql.DB, dbPath string, version int) error {
+	if dbPath == "" || dbPath == MemoryDB {
+		return nil
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil
+	}
+	backupPath := fmt.Sprintf("%s.v%d-%s.bak", dbPath, version, time.Now().Format("20060102-150405"))
+	if _, err := db.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+		return err
+	}
+	return nil
+}
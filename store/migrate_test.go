@@ -0,0 +1,157 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// legacySchema is the schema navi created before schema_version existed.
+var legacySchema = []string{
+	`CREATE TABLE tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		path TEXT NOT NULL,
+		UNIQUE(name, path)
+	);`,
+	`CREATE TABLE settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);`,
+	`CREATE TABLE history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		path TEXT NOT NULL UNIQUE,
+		frequency INTEGER DEFAULT 1,
+		last_visited TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`,
+	`INSERT INTO tags (name, path) VALUES ('work', '/home/user/work');`,
+	`INSERT INTO settings (key, value) VALUES ('default_action', 'editor');`,
+	`INSERT INTO history (path, frequency) VALUES ('/home/user/work', 7);`,
+}
+
+func writeFixture(t *testing.T, queries []string) string {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "navi.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, q := range queries {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("fixture query failed: %v", err)
+		}
+	}
+	return dbPath
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	dbPath := writeFixture(t, legacySchema)
+
+	db, err := InitDB(dbPath)
+	if err != nil {
+		t.Fatalf("InitDB on legacy db failed: %v", err)
+	}
+	defer db.Close()
+
+	version, err := schemaVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := migrations[len(migrations)-1].version; version != latest {
+		t.Errorf("expected schema version %d, got %d", latest, version)
+	}
+
+	paths, err := GetPathsForTag(db, "work")
+	if err != nil || len(paths) != 1 {
+		t.Errorf("expected legacy tag to survive, got %v (%v)", paths, err)
+	}
+	if v, _ := GetSetting(db, "default_action"); v != "editor" {
+		t.Errorf("expected legacy setting to survive, got %q", v)
+	}
+	history, err := GetHistory(db)
+	if err != nil || len(history) != 1 || history[0].Frequency != 7 {
+		t.Errorf("expected legacy history to survive, got %v (%v)", history, err)
+	}
+
+	// Re-opening an up-to-date database is a no-op
+	db.Close()
+	db, err = InitDB(dbPath)
+	if err != nil {
+		t.Fatalf("InitDB on migrated db failed: %v", err)
+	}
+	defer db.Close()
+}
+
+func TestMigrateRollsBackFailedStep(t *testing.T) {
+	dbPath := writeFixture(t, legacySchema)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	steps := append([]migration{}, migrations...)
+	steps = append(steps, migration{
+		version: 1000,
+		name:    "broken",
+		up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`CREATE TABLE half_done (id INTEGER)`); err != nil {
+				return err
+			}
+			return fmt.Errorf("boom")
+		},
+	})
+
+	if err := migrate(db, dbPath, steps); err == nil {
+		t.Fatal("expected migration error")
+	}
+	version, _ := schemaVersion(db)
+	if version != migrations[len(migrations)-1].version {
+		t.Errorf("expected version to stay at the last good migration, got %d", version)
+	}
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'`).Scan(&n)
+	if n != 0 {
+		t.Errorf("expected failed migration to be rolled back")
+	}
+}
+
+func TestMigrateBacksUpBeforeDestructiveStep(t *testing.T) {
+	dbPath := writeFixture(t, legacySchema)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	steps := append([]migration{}, migrations...)
+	steps = append(steps, migration{
+		version:     1000,
+		name:        "drop history",
+		destructive: true,
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`DROP TABLE history`)
+			return err
+		},
+	})
+
+	if err := migrate(db, dbPath, steps); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	backups, _ := filepath.Glob(dbPath + ".v*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup file, got %v", backups)
+	}
+	backup, err := sql.Open("sqlite3", backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	history, err := GetHistory(backup)
+	if err != nil || len(history) != 1 {
+		t.Errorf("expected backup to contain the history table, got %v (%v)", history, err)
+	}
+}
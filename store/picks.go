@@ -0,0 +1,75 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QueryPick is a path picked after typing a query, with how often it was
+// picked for that query (or longer queries starting with it).
+type QueryPick struct {
+	Path       string    `json:"path"`
+	Count      int       `json:"count"`
+	LastPicked time.Time `json:"last_picked"`
+}
+
+// NormalizeQuery lowercases query and collapses its whitespace, so "Cfg "
+// and "cfg" are remembered as the same query.
+func NormalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// RecordQueryPick remembers that path was picked after typing query.
+func RecordQueryPick(db *sql.DB, query, path string) error {
+	query = NormalizeQuery(query)
+	if query == "" {
+		return nil
+	}
+	_, err := db.Exec(`
+		INSERT INTO query_picks (query, path, count, last_picked)
+		VALUES (?, ?, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT(query, path) DO UPDATE SET
+			count = count + 1,
+			last_picked = CURRENT_TIMESTAMP
+	`, query, path)
+	if err != nil {
+		return fmt.Errorf("failed to record query pick: %w", err)
+	}
+	return nil
+}
+
+// GetQueryPicks returns the paths picked for query or for a longer query
+// starting with it, best first: picks for exactly query, then by count and
+// last pick.
+func GetQueryPicks(db *sql.DB, query string) ([]QueryPick, error) {
+	query = NormalizeQuery(query)
+	if query == "" {
+		return nil, nil
+	}
+	rows, err := db.Query(`
+		SELECT path, SUM(count), MAX(last_picked), MAX(query = ?) AS exact
+		FROM query_picks
+		WHERE substr(query, 1, length(?)) = ?
+		GROUP BY path
+		ORDER BY exact DESC, SUM(count) DESC, MAX(last_picked) DESC, path
+	`, query, query, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query picks: %w", err)
+	}
+	defer rows.Close()
+
+	var picks []QueryPick
+	for rows.Next() {
+		var p QueryPick
+		var lastPicked string
+		var exact bool
+		if err := rows.Scan(&p.Path, &p.Count, &lastPicked, &exact); err != nil {
+			return nil, err
+		}
+		p.LastPicked, _ = time.Parse(sqliteTime, lastPicked)
+		picks = append(picks, p)
+	}
+	return picks, nil
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// AddPin pins path after the existing pins; pinned paths are left in place.
+func AddPin(db *sql.DB, path string) error {
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO pins (path, position)
+		VALUES (?, (SELECT COALESCE(MAX(position), 0) + 1 FROM pins))
+	`, path)
+	if err != nil {
+		return fmt.Errorf("failed to add pin: %w", err)
+	}
+	return nil
+}
+
+// GetPins returns the pinned paths in the user's order.
+func GetPins(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT path FROM pins ORDER BY position, path`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pins: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// RemovePin unpins path. It reports whether the path was pinned.
+func RemovePin(db *sql.DB, path string) (bool, error) {
+	res, err := db.Exec(`DELETE FROM pins WHERE path = ?`, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove pin: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SetPins replaces the pins with paths, in that order.
+func SetPins(db *sql.DB, paths []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to set pins: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM pins`); err != nil {
+		return fmt.Errorf("failed to set pins: %w", err)
+	}
+	for i, p := range paths {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO pins (path, position) VALUES (?, ?)`, p, i+1); err != nil {
+			return fmt.Errorf("failed to set pins: %w", err)
+		}
+	}
+	return tx.Commit()
+}
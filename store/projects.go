@@ -0,0 +1,79 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AddProjects records detected project roots; known ones are ignored.
+func AddProjects(db *sql.DB, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to add projects: %w", err)
+	}
+	defer tx.Rollback()
+	for _, p := range paths {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO projects (path) VALUES (?)`, p); err != nil {
+			return fmt.Errorf("failed to add project: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetProjects returns every known project root.
+func GetProjects(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT path FROM projects ORDER BY path`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// RemoveProject forgets a project root. It reports whether it was known.
+func RemoveProject(db *sql.DB, path string) (bool, error) {
+	res, err := db.Exec(`DELETE FROM projects WHERE path = ?`, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove project: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RankProjects orders projects by the summed frecency of every history
+// entry at or inside them, then by path. Unvisited projects come last.
+func RankProjects(projects []string, history []HistoryItem, now time.Time) []string {
+	score := make(map[string]float64, len(projects))
+	for _, p := range projects {
+		prefix := p + string(filepath.Separator)
+		for _, h := range history {
+			if h.Path == p || strings.HasPrefix(h.Path, prefix) {
+				score[p] += h.Frecency(now)
+			}
+		}
+	}
+	ranked := append([]string(nil), projects...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if score[ranked[i]] != score[ranked[j]] {
+			return score[ranked[i]] > score[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	return ranked
+}
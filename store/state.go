@@ -0,0 +1,259 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// StateVersion is the format version written by ExportState.
+const StateVersion = 1
+
+// TagEntry is one (tag, path) membership.
+type TagEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// ContextEntry is the history of one path visited from one context.
+type ContextEntry struct {
+	Context string `json:"context"`
+	HistoryItem
+}
+
+// PickEntry is one path picked after typing a (normalized) query.
+type PickEntry struct {
+	Query string `json:"query"`
+	QueryPick
+}
+
+// State is a portable snapshot of tags, tag definitions, history (global and
+// per context), query picks, pins, aliases and settings, used by
+// `navi export` and `navi import`.
+type State struct {
+	Version  int               `json:"version"`
+	Tags     []TagEntry        `json:"tags"`
+	TagDefs  []TagDef          `json:"tag_defs,omitempty"`
+	History  []HistoryItem     `json:"history"`
+	Contexts []ContextEntry    `json:"contexts,omitempty"`
+	Picks    []PickEntry       `json:"picks,omitempty"`
+	Pins     []string          `json:"pins,omitempty"`
+	Aliases  map[string]string `json:"aliases,omitempty"`
+	Settings map[string]string `json:"settings"`
+}
+
+// ExportState reads every table into a State.
+func ExportState(db *sql.DB) (State, error) {
+	state := State{Version: StateVersion, Settings: make(map[string]string)}
+
+	rows, err := db.Query(`SELECT name, path FROM tags ORDER BY name, path`)
+	if err != nil {
+		return state, fmt.Errorf("failed to export tags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t TagEntry
+		if err := rows.Scan(&t.Name, &t.Path); err != nil {
+			return state, err
+		}
+		state.Tags = append(state.Tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		return state, fmt.Errorf("failed to export tags: %w", err)
+	}
+
+	defs, err := GetTagDefs(db)
+	if err != nil {
+		return state, err
+	}
+	for _, name := range sortedKeys(boolSet(defs)) {
+		state.TagDefs = append(state.TagDefs, defs[name])
+	}
+
+	if state.History, err = GetHistory(db); err != nil {
+		return state, err
+	}
+	if state.Contexts, err = exportContextHistory(db); err != nil {
+		return state, err
+	}
+	if state.Picks, err = exportQueryPicks(db); err != nil {
+		return state, err
+	}
+	if state.Pins, err = GetPins(db); err != nil {
+		return state, err
+	}
+	aliases, err := GetAliases(db)
+	if err != nil {
+		return state, err
+	}
+	if len(aliases) > 0 {
+		state.Aliases = aliases
+	}
+
+	settings, err := db.Query(`SELECT key, value FROM settings ORDER BY key`)
+	if err != nil {
+		return state, fmt.Errorf("failed to export settings: %w", err)
+	}
+	defer settings.Close()
+	for settings.Next() {
+		var k, v string
+		if err := settings.Scan(&k, &v); err != nil {
+			return state, err
+		}
+		state.Settings[k] = v
+	}
+	if err := settings.Err(); err != nil {
+		return state, fmt.Errorf("failed to export settings: %w", err)
+	}
+	return state, nil
+}
+
+// ImportState loads a State in one transaction. With replace, the existing
+// tags, history, pins, aliases and settings are cleared first; otherwise tags
+// are added, global and context history and query picks are merged (see
+// MergeHistory), new pins go after the existing ones and imported aliases and
+// settings win.
+func ImportState(db *sql.DB, state State, replace bool) error {
+	if state.Version > StateVersion {
+		return fmt.Errorf("state version %d is newer than supported version %d", state.Version, StateVersion)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to import state: %w", err)
+	}
+	defer tx.Rollback()
+
+	if replace {
+		if err := execAll(tx, `DELETE FROM tags`, `DELETE FROM tag_defs`, `DELETE FROM history`, `DELETE FROM context_history`, `DELETE FROM query_picks`, `DELETE FROM pins`, `DELETE FROM aliases`, `DELETE FROM settings`); err != nil {
+			return fmt.Errorf("failed to clear state: %w", err)
+		}
+	}
+
+	for _, t := range state.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name, path) VALUES (?, ?)`, t.Name, t.Path); err != nil {
+			return fmt.Errorf("failed to import tag: %w", err)
+		}
+	}
+	for _, def := range state.TagDefs {
+		_, err := tx.Exec(`
+			INSERT INTO tag_defs (name, description, color, icon, action) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(name) DO UPDATE SET
+				description = excluded.description,
+				color = excluded.color,
+				icon = excluded.icon,
+				action = excluded.action
+		`, def.Name, def.Description, def.Color, def.Icon, def.Action)
+		if err != nil {
+			return fmt.Errorf("failed to import tag definition: %w", err)
+		}
+	}
+	for _, h := range state.History {
+		_, err := tx.Exec(`
+			INSERT INTO history (path, frequency, last_visited)
+			VALUES (?, ?, ?)
+			ON CONFLICT(path) DO UPDATE SET
+				frequency = MAX(frequency, excluded.frequency),
+				last_visited = MAX(last_visited, excluded.last_visited)
+		`, h.Path, h.Frequency, h.LastVisited.UTC().Format(sqliteTime))
+		if err != nil {
+			return fmt.Errorf("failed to import history: %w", err)
+		}
+	}
+	for _, c := range state.Contexts {
+		_, err := tx.Exec(`
+			INSERT INTO context_history (context, path, frequency, last_visited)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(context, path) DO UPDATE SET
+				frequency = MAX(frequency, excluded.frequency),
+				last_visited = MAX(last_visited, excluded.last_visited)
+		`, c.Context, c.Path, c.Frequency, c.LastVisited.UTC().Format(sqliteTime))
+		if err != nil {
+			return fmt.Errorf("failed to import context history: %w", err)
+		}
+	}
+	for _, p := range state.Picks {
+		_, err := tx.Exec(`
+			INSERT INTO query_picks (query, path, count, last_picked)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(query, path) DO UPDATE SET
+				count = MAX(count, excluded.count),
+				last_picked = MAX(last_picked, excluded.last_picked)
+		`, p.Query, p.Path, p.Count, p.LastPicked.UTC().Format(sqliteTime))
+		if err != nil {
+			return fmt.Errorf("failed to import query pick: %w", err)
+		}
+	}
+	for _, p := range state.Pins {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO pins (path, position)
+			VALUES (?, (SELECT COALESCE(MAX(position), 0) + 1 FROM pins))
+		`, p)
+		if err != nil {
+			return fmt.Errorf("failed to import pin: %w", err)
+		}
+	}
+	for name, path := range state.Aliases {
+		_, err := tx.Exec(`
+			INSERT INTO aliases (name, path) VALUES (?, ?)
+			ON CONFLICT(name) DO UPDATE SET path = excluded.path
+		`, name, path)
+		if err != nil {
+			return fmt.Errorf("failed to import alias %q: %w", name, err)
+		}
+	}
+	for k, v := range state.Settings {
+		_, err := tx.Exec(`
+			INSERT INTO settings (key, value) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value
+		`, k, v)
+		if err != nil {
+			return fmt.Errorf("failed to import setting %q: %w", k, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// exportContextHistory reads every context's visits, grouped by context and
+// most recent first within each.
+func exportContextHistory(db *sql.DB) ([]ContextEntry, error) {
+	rows, err := db.Query(`SELECT context, path, frequency, last_visited FROM context_history ORDER BY context, last_visited DESC, path`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export context history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []ContextEntry
+	for rows.Next() {
+		var e ContextEntry
+		if err := rows.Scan(&e.Context, &e.Path, &e.Frequency, &e.LastVisited); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export context history: %w", err)
+	}
+	return entries, nil
+}
+
+// exportQueryPicks reads every remembered query pick, by query and path.
+func exportQueryPicks(db *sql.DB) ([]PickEntry, error) {
+	rows, err := db.Query(`SELECT query, path, count, last_picked FROM query_picks ORDER BY query, path`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export query picks: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []PickEntry
+	for rows.Next() {
+		var e PickEntry
+		if err := rows.Scan(&e.Query, &e.Path, &e.Count, &e.LastPicked); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export query picks: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,250 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Store is the persistence API used by the TUI and CLI. SQLiteStore is the
+// real implementation; MemStore is an in-memory fake for tests.
+type Store interface {
+	// Tags
+	AddPathToTag(tagName, path string) error
+	RemovePathFromTag(tagName, path string) error
+	GetPathsForTag(tagName string) ([]string, error)
+	GetPathsForTagQuery(q TagQuery) ([]string, error)
+	GetAllTaggedPaths() ([]string, error)
+	GetTagsForPath(path string) ([]string, error)
+	GetAllTags() ([]string, error)
+	GetTagsByPath() (map[string][]string, error)
+	GetTagCounts() (map[string]int, error)
+	RenameTag(oldName, newName string) error
+	DeleteTag(name string) error
+	GetTagDef(name string) (TagDef, error)
+	GetTagDefs() (map[string]TagDef, error)
+	SetTagDef(def TagDef) error
+
+	// History
+	UpdateFrecency(path string) error
+	GetHistory() ([]HistoryItem, error)
+	GetRecentHistory(limit int) ([]HistoryItem, error)
+	RemoveHistory(path string) (bool, error)
+	PruneHistory(cutoff time.Time) (int, error)
+	AgeHistory(maxTotal int) (int, error)
+	MergeHistory(items []HistoryItem) error
+	UpdateContextFrecency(context, path string) error
+	GetContextHistory(context string) ([]HistoryItem, error)
+	RecordQueryPick(query, path string) error
+	GetQueryPicks(query string) ([]QueryPick, error)
+
+	// Projects
+	AddProjects(paths []string) error
+	GetProjects() ([]string, error)
+	RemoveProject(path string) (bool, error)
+
+	// Pins
+	AddPin(path string) error
+	GetPins() ([]string, error)
+	RemovePin(path string) (bool, error)
+	SetPins(paths []string) error
+
+	// Aliases
+	SetAlias(name, path string) error
+	GetAlias(name string) (string, error)
+	GetAliases() (map[string]string, error)
+	RemoveAlias(name string) (bool, error)
+
+	// Settings
+	GetSetting(key string) (string, error)
+	SetSetting(key, value string) error
+
+	// Whole-state snapshots
+	ExportState() (State, error)
+	ImportState(state State, replace bool) error
+
+	Close() error
+}
+
+// SQLiteStore implements Store on top of the navi SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// Open initializes the database at dbPath (see InitDB) and wraps it in a Store.
+func Open(dbPath string) (*SQLiteStore, error) {
+	db, err := InitDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// NewSQLiteStore wraps an already initialized database.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// DB returns the underlying database handle.
+func (s *SQLiteStore) DB() *sql.DB { return s.db }
+
+func (s *SQLiteStore) Close() error { return s.db.Close() }
+
+func (s *SQLiteStore) AddPathToTag(tagName, path string) error {
+	return AddPathToTag(s.db, tagName, path)
+}
+
+func (s *SQLiteStore) RemovePathFromTag(tagName, path string) error {
+	return RemovePathFromTag(s.db, tagName, path)
+}
+
+func (s *SQLiteStore) GetPathsForTag(tagName string) ([]string, error) {
+	return GetPathsForTag(s.db, tagName)
+}
+
+func (s *SQLiteStore) GetPathsForTagQuery(q TagQuery) ([]string, error) {
+	return GetPathsForTagQuery(s.db, q)
+}
+
+func (s *SQLiteStore) GetAllTaggedPaths() ([]string, error) {
+	return GetAllTaggedPaths(s.db)
+}
+
+func (s *SQLiteStore) GetTagsForPath(path string) ([]string, error) {
+	return GetTagsForPath(s.db, path)
+}
+
+func (s *SQLiteStore) GetAllTags() ([]string, error) {
+	return GetAllTags(s.db)
+}
+
+func (s *SQLiteStore) GetTagsByPath() (map[string][]string, error) {
+	return GetTagsByPath(s.db)
+}
+
+func (s *SQLiteStore) GetTagCounts() (map[string]int, error) {
+	return GetTagCounts(s.db)
+}
+
+func (s *SQLiteStore) RenameTag(oldName, newName string) error {
+	return RenameTag(s.db, oldName, newName)
+}
+
+func (s *SQLiteStore) DeleteTag(name string) error {
+	return DeleteTag(s.db, name)
+}
+
+func (s *SQLiteStore) GetTagDef(name string) (TagDef, error) {
+	return GetTagDef(s.db, name)
+}
+
+func (s *SQLiteStore) GetTagDefs() (map[string]TagDef, error) {
+	return GetTagDefs(s.db)
+}
+
+func (s *SQLiteStore) SetTagDef(def TagDef) error {
+	return SetTagDef(s.db, def)
+}
+
+func (s *SQLiteStore) UpdateFrecency(path string) error {
+	return UpdateFrecency(s.db, path)
+}
+
+func (s *SQLiteStore) GetHistory() ([]HistoryItem, error) {
+	return GetHistory(s.db)
+}
+
+func (s *SQLiteStore) GetRecentHistory(limit int) ([]HistoryItem, error) {
+	return GetRecentHistory(s.db, limit)
+}
+
+func (s *SQLiteStore) RemoveHistory(path string) (bool, error) {
+	return RemoveHistory(s.db, path)
+}
+
+func (s *SQLiteStore) PruneHistory(cutoff time.Time) (int, error) {
+	return PruneHistory(s.db, cutoff)
+}
+
+func (s *SQLiteStore) AgeHistory(maxTotal int) (int, error) {
+	return AgeHistory(s.db, maxTotal)
+}
+
+func (s *SQLiteStore) MergeHistory(items []HistoryItem) error {
+	return MergeHistory(s.db, items)
+}
+
+func (s *SQLiteStore) UpdateContextFrecency(context, path string) error {
+	return UpdateContextFrecency(s.db, context, path)
+}
+
+func (s *SQLiteStore) GetContextHistory(context string) ([]HistoryItem, error) {
+	return GetContextHistory(s.db, context)
+}
+
+func (s *SQLiteStore) RecordQueryPick(query, path string) error {
+	return RecordQueryPick(s.db, query, path)
+}
+
+func (s *SQLiteStore) GetQueryPicks(query string) ([]QueryPick, error) {
+	return GetQueryPicks(s.db, query)
+}
+
+func (s *SQLiteStore) AddProjects(paths []string) error {
+	return AddProjects(s.db, paths)
+}
+
+func (s *SQLiteStore) GetProjects() ([]string, error) {
+	return GetProjects(s.db)
+}
+
+func (s *SQLiteStore) RemoveProject(path string) (bool, error) {
+	return RemoveProject(s.db, path)
+}
+
+func (s *SQLiteStore) AddPin(path string) error {
+	return AddPin(s.db, path)
+}
+
+func (s *SQLiteStore) GetPins() ([]string, error) {
+	return GetPins(s.db)
+}
+
+func (s *SQLiteStore) RemovePin(path string) (bool, error) {
+	return RemovePin(s.db, path)
+}
+
+func (s *SQLiteStore) SetPins(paths []string) error {
+	return SetPins(s.db, paths)
+}
+
+func (s *SQLiteStore) SetAlias(name, path string) error {
+	return SetAlias(s.db, name, path)
+}
+
+func (s *SQLiteStore) GetAlias(name string) (string, error) {
+	return GetAlias(s.db, name)
+}
+
+func (s *SQLiteStore) GetAliases() (map[string]string, error) {
+	return GetAliases(s.db)
+}
+
+func (s *SQLiteStore) RemoveAlias(name string) (bool, error) {
+	return RemoveAlias(s.db, name)
+}
+
+func (s *SQLiteStore) GetSetting(key string) (string, error) {
+	return GetSetting(s.db, key)
+}
+
+func (s *SQLiteStore) SetSetting(key, value string) error {
+	return SetSetting(s.db, key, value)
+}
+
+func (s *SQLiteStore) ExportState() (State, error) {
+	return ExportState(s.db)
+}
+
+func (s *SQLiteStore) ImportState(state State, replace bool) error {
+	return ImportState(s.db, state, replace)
+}
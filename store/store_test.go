@@ -1,21 +1,44 @@
 package store
 
 import (
-	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
-func TestStore(t *testing.T) {
-	// Use a temp file for testing
-	tmpFile, err := os.CreateTemp("", "navi-test-*.db")
-	if err != nil {
-		t.Fatal(err)
+func TestResolveDBPath(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("NAVI_DATA_DIR", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	check := func(override, want string) {
+		t.Helper()
+		got, err := ResolveDBPath(override)
+		if err != nil {
+			t.Fatalf("ResolveDBPath failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
 	}
-	dbPath := tmpFile.Name()
-	tmpFile.Close()
-	defer os.Remove(dbPath)
 
-	db, err := InitDB(dbPath)
+	check("", filepath.Join("/home/user", ".local", "share", "navi", "navi.db"))
+
+	t.Setenv("XDG_DATA_HOME", "relative/ignored")
+	check("", filepath.Join("/home/user", ".local", "share", "navi", "navi.db"))
+
+	t.Setenv("XDG_DATA_HOME", "/xdg")
+	check("", filepath.Join("/xdg", "navi", "navi.db"))
+
+	t.Setenv("NAVI_DATA_DIR", "/data")
+	check("", filepath.Join("/data", "navi.db"))
+
+	check("/tmp/custom.db", "/tmp/custom.db")
+	check(MemoryDB, MemoryDB)
+}
+
+func TestStore(t *testing.T) {
+	db, err := InitDB(MemoryDB)
 	if err != nil {
 		t.Fatalf("InitDB failed: %v", err)
 	}
@@ -60,6 +83,20 @@ func TestStore(t *testing.T) {
 		if paths[0] != path2 {
 			t.Errorf("expected %s, got %s", path2, paths[0])
 		}
+
+		if err := AddPathToTag(db, "@other", path2); err != nil {
+			t.Fatalf("AddPathToTag failed: %v", err)
+		}
+		byPath, err := GetTagsByPath(db)
+		if err != nil {
+			t.Fatalf("GetTagsByPath failed: %v", err)
+		}
+		if got := byPath[path2]; len(got) != 2 || got[0] != "@other" || got[1] != tagName {
+			t.Errorf("expected [@other %s] for %s, got %v", tagName, path2, got)
+		}
+		if _, ok := byPath[path1]; ok {
+			t.Errorf("expected no tags for removed path %s", path1)
+		}
 	})
 
 	// Test 2: History
@@ -99,3 +136,21 @@ func TestStore(t *testing.T) {
 		}
 	})
 }
+
+func TestRankProjects(t *testing.T) {
+	now := time.Now()
+	history := []HistoryItem{
+		{Path: "/p/web/src/app.js", Frequency: 2, LastVisited: now},
+		{Path: "/p/api", Frequency: 1, LastVisited: now},
+		{Path: "/p/api/cmd", Frequency: 5, LastVisited: now},
+		{Path: "/p/apix", Frequency: 50, LastVisited: now}, // not inside /p/api
+	}
+	got := RankProjects([]string{"/p/docs", "/p/web", "/p/api"}, history, now)
+	want := []string{"/p/api", "/p/web", "/p/docs"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// TagDef holds a tag's display metadata. Tags without a definition behave
+// as if they had an empty one.
+type TagDef struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`  // lipgloss color, e.g. "205" or "#ff8800"
+	Icon        string `json:"icon,omitempty"`   // short glyph shown before the tag name
+	Action      string `json:"action,omitempty"` // default action while the tag is active
+}
+
+// IsZero reports whether def carries no metadata besides its name.
+func (def TagDef) IsZero() bool {
+	return def.Description == "" && def.Color == "" && def.Icon == "" && def.Action == ""
+}
+
+// GetTagDef returns the definition of a tag, or an empty one if it has none.
+func GetTagDef(db *sql.DB, name string) (TagDef, error) {
+	def := TagDef{Name: name}
+	query := `SELECT description, color, icon, action FROM tag_defs WHERE name = ?`
+	err := db.QueryRow(query, name).Scan(&def.Description, &def.Color, &def.Icon, &def.Action)
+	if err == sql.ErrNoRows {
+		return def, nil
+	}
+	if err != nil {
+		return def, fmt.Errorf("failed to get tag definition: %w", err)
+	}
+	return def, nil
+}
+
+// GetTagDefs returns every tag definition keyed by tag name.
+func GetTagDefs(db *sql.DB) (map[string]TagDef, error) {
+	query := `SELECT name, description, color, icon, action FROM tag_defs ORDER BY name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag definitions: %w", err)
+	}
+	defer rows.Close()
+
+	defs := make(map[string]TagDef)
+	for rows.Next() {
+		var def TagDef
+		if err := rows.Scan(&def.Name, &def.Description, &def.Color, &def.Icon, &def.Action); err != nil {
+			return nil, err
+		}
+		defs[def.Name] = def
+	}
+	return defs, nil
+}
+
+// SetTagDef creates or replaces a tag definition. An empty definition is deleted.
+func SetTagDef(db *sql.DB, def TagDef) error {
+	if def.IsZero() {
+		if _, err := db.Exec(`DELETE FROM tag_defs WHERE name = ?`, def.Name); err != nil {
+			return fmt.Errorf("failed to delete tag definition: %w", err)
+		}
+		return nil
+	}
+	query := `
+		INSERT INTO tag_defs (name, description, color, icon, action) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			description = excluded.description,
+			color = excluded.color,
+			icon = excluded.icon,
+			action = excluded.action
+	`
+	if _, err := db.Exec(query, def.Name, def.Description, def.Color, def.Icon, def.Action); err != nil {
+		return fmt.Errorf("failed to set tag definition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// TagQuery is a boolean tag filter: a path matches if, for every group in
+// All, it has at least one of the group's tags, and it has none of None.
+//
+// In the search input `@work @go` is two groups (intersection),
+// `@work,@personal` is one group (union) and `-@archive` is an exclusion.
+type TagQuery struct {
+	All  [][]string
+	None []string
+}
+
+// IsEmpty reports whether the query has no terms.
+func (q TagQuery) IsEmpty() bool {
+	return len(q.All) == 0 && len(q.None) == 0
+}
+
+// String renders the query in input syntax without the leading '@', so a
+// single-tag query is just the tag name: "work", "work @go,@node -@archive".
+func (q TagQuery) String() string {
+	var terms []string
+	for _, group := range q.All {
+		terms = append(terms, "@"+strings.Join(group, ",@"))
+	}
+	for _, name := range q.None {
+		terms = append(terms, "-@"+name)
+	}
+	return strings.TrimPrefix(strings.Join(terms, " "), "@")
+}
+
+// Tags returns every tag name the query mentions.
+func (q TagQuery) Tags() []string {
+	var names []string
+	for _, group := range q.All {
+		names = append(names, group...)
+	}
+	return append(names, q.None...)
+}
+
+// Match reports whether a path with the given tags satisfies the query.
+func (q TagQuery) Match(tags []string) bool {
+	has := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		has[t] = true
+	}
+	for _, name := range q.None {
+		if has[name] {
+			return false
+		}
+	}
+	for _, group := range q.All {
+		found := false
+		for _, name := range group {
+			if has[name] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(tags) > 0
+}
+
+// IsTagTerm reports whether a query token is a tag term (`@a`, `@a,@b` or `-@a`).
+func IsTagTerm(token string) bool {
+	return strings.HasPrefix(token, "@") || strings.HasPrefix(token, "-@")
+}
+
+// ValidateTagName rejects tag names that can't be typed as a single query
+// term: empty, containing whitespace or ',' (which split terms), or starting
+// with '@' or '-' (which read as part of the term's prefix).
+func ValidateTagName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("tag name is empty")
+	case strings.ContainsAny(name, " \t\n,"):
+		return fmt.Errorf("tag name %q must not contain spaces or commas", name)
+	case strings.ContainsAny(name[:1], "@-"):
+		return fmt.Errorf("tag name %q must not start with @ or -", name)
+	}
+	return nil
+}
+
+// ParseTagQuery splits the tag terms at the start of a search input from the
+// rest of the query. A term only counts once it is followed by a space, so a
+// tag still being typed stays in rest.
+func ParseTagQuery(input string) (TagQuery, string) {
+	var q TagQuery
+	rest := input
+	for IsTagTerm(rest) {
+		token, after, complete := strings.Cut(rest, " ")
+		if !complete {
+			break
+		}
+		rest = after
+
+		if name, ok := strings.CutPrefix(token, "-@"); ok {
+			if name != "" {
+				q.None = append(q.None, name)
+			}
+			continue
+		}
+		var group []string
+		for _, name := range strings.Split(token, ",") {
+			if name = strings.TrimPrefix(name, "@"); name != "" {
+				group = append(group, name)
+			}
+		}
+		if len(group) > 0 {
+			q.All = append(q.All, group)
+		}
+	}
+	return q, rest
+}
+
+// GetPathsForTagQuery returns the tagged paths matching q in a single query.
+func GetPathsForTagQuery(db *sql.DB, q TagQuery) ([]string, error) {
+	if q.IsEmpty() {
+		return nil, nil
+	}
+
+	var where []string
+	var args []any
+	in := func(names []string) string {
+		for _, name := range names {
+			args = append(args, name)
+		}
+		return strings.TrimSuffix(strings.Repeat("?,", len(names)), ",")
+	}
+	for _, group := range q.All {
+		where = append(where, `EXISTS (SELECT 1 FROM tags g WHERE g.path = t.path AND g.name IN (`+in(group)+`))`)
+	}
+	if len(q.None) > 0 {
+		where = append(where, `NOT EXISTS (SELECT 1 FROM tags x WHERE x.path = t.path AND x.name IN (`+in(q.None)+`))`)
+	}
+
+	query := `SELECT DISTINCT t.path FROM tags t WHERE ` + strings.Join(where, " AND ") + ` ORDER BY t.path`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paths for tag query: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTagQuery(t *testing.T) {
+	cases := []struct {
+		input string
+		want  TagQuery
+		rest  string
+		str   string
+	}{
+		{"@work main", TagQuery{All: [][]string{{"work"}}}, "main", "work"},
+		{"@work @go api handler", TagQuery{All: [][]string{{"work"}, {"go"}}}, "api handler", "work @go"},
+		{"@work,@personal notes", TagQuery{All: [][]string{{"work", "personal"}}}, "notes", "work,@personal"},
+		{"@go -@archive ", TagQuery{All: [][]string{{"go"}}, None: []string{"archive"}}, "", "go -@archive"},
+		{"@work @g", TagQuery{All: [][]string{{"work"}}}, "@g", "work"},
+		{"@wo", TagQuery{}, "@wo", ""},
+		{"main @work ", TagQuery{}, "main @work ", ""},
+	}
+	for _, c := range cases {
+		q, rest := ParseTagQuery(c.input)
+		if !reflect.DeepEqual(q, c.want) || rest != c.rest {
+			t.Errorf("%q: expected %+v rest %q, got %+v rest %q", c.input, c.want, c.rest, q, rest)
+		}
+		if q.String() != c.str {
+			t.Errorf("%q: expected String() %q, got %q", c.input, c.str, q.String())
+		}
+	}
+}
+
+func TestTagQueryMatch(t *testing.T) {
+	q, _ := ParseTagQuery("@work,@personal @go -@archive ")
+	cases := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"work", "go"}, true},
+		{[]string{"personal", "go"}, true},
+		{[]string{"work"}, false},
+		{[]string{"work", "go", "archive"}, false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := q.Match(c.tags); got != c.want {
+			t.Errorf("%v: expected %v, got %v", c.tags, c.want, got)
+		}
+	}
+}
+
+func TestValidateTagName(t *testing.T) {
+	for _, name := range []string{"work", "go-lib", "a_b", "日本"} {
+		if err := ValidateTagName(name); err != nil {
+			t.Errorf("expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a b", "a,b", "-a", "@a", "a\tb"} {
+		if err := ValidateTagName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
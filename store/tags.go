@@ -95,6 +95,26 @@ func GetAllTags(db *sql.DB) ([]string, error) {
 	return tags, nil
 }
 
+// GetTagsByPath returns every tagged path mapped to its tag names.
+func GetTagsByPath(db *sql.DB) (map[string][]string, error) {
+	query := `SELECT path, name FROM tags ORDER BY path, name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags by path: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var path, name string
+		if err := rows.Scan(&path, &name); err != nil {
+			return nil, err
+		}
+		tags[path] = append(tags[path], name)
+	}
+	return tags, nil
+}
+
 // RemovePathFromTag removes a path from a specific tag.
 func RemovePathFromTag(db *sql.DB, tagName, path string) error {
 	query := `DELETE FROM tags WHERE name = ? AND path = ?`
@@ -104,3 +124,78 @@ func RemovePathFromTag(db *sql.DB, tagName, path string) error {
 	}
 	return nil
 }
+
+// GetTagCounts returns the number of paths in every tag.
+func GetTagCounts(db *sql.DB) (map[string]int, error) {
+	query := `SELECT name, COUNT(*) FROM tags GROUP BY name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var n int
+		if err := rows.Scan(&name, &n); err != nil {
+			return nil, err
+		}
+		counts[name] = n
+	}
+	return counts, nil
+}
+
+// RenameTag moves every path and the definition of oldName to newName.
+// If newName already exists the tags are merged; its own definition wins.
+func RenameTag(db *sql.DB, oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("failed to rename tag: new name is empty")
+	}
+	if oldName == newName {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to rename tag: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name, path) SELECT ?, path FROM tags WHERE name = ?`, newName, oldName); err != nil {
+		return fmt.Errorf("failed to rename tag: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO tag_defs (name, description, color, icon, action)
+		SELECT ?, description, color, icon, action FROM tag_defs WHERE name = ?
+	`, newName, oldName); err != nil {
+		return fmt.Errorf("failed to rename tag definition: %w", err)
+	}
+	if err := execDelete(tx, oldName); err != nil {
+		return fmt.Errorf("failed to rename tag: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteTag removes a tag from every path along with its definition.
+func DeleteTag(db *sql.DB, name string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := execDelete(tx, name); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return tx.Commit()
+}
+
+// execDelete removes a tag's memberships and definition inside tx.
+func execDelete(tx *sql.Tx, name string) error {
+	if _, err := tx.Exec(`DELETE FROM tags WHERE name = ?`, name); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM tag_defs WHERE name = ?`, name)
+	return err
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/store"
+	"github.com/montrey/navi/ui"
+)
+
+// tagManager is the state of the global tag manager (Ctrl+G): every tag with
+// its member count, and the paths of the tag drilled into.
+type tagManager struct {
+	tags     []string
+	counts   map[string]int
+	selected int
+
+	tag          string // Tag drilled into; empty at the tag list
+	paths        []string
+	pathSelected int
+
+	renaming      bool
+	confirmDelete bool
+	err           error
+}
+
+// openTagManager switches to the tag manager showing every tag.
+func (m model) openTagManager() model {
+	m.tagManager = tagManager{}
+	m.reloadTagManager()
+	m.tagInput.SetValue("")
+	m.tagInput.Blur()
+	m.mode = modeTagManager
+	return m
+}
+
+// reloadTagManager refreshes tags, counts and the drilled-in tag's paths,
+// keeping the selections in range.
+func (m *model) reloadTagManager() {
+	tm := &m.tagManager
+	tm.tags, _ = m.db.GetAllTags()
+	tm.counts, _ = m.db.GetTagCounts()
+	m.tagDefs, _ = m.db.GetTagDefs()
+	tm.selected = clampIndex(tm.selected, len(tm.tags))
+	if tm.tag != "" {
+		tm.paths, _ = m.db.GetPathsForTag(tm.tag)
+		tm.pathSelected = clampIndex(tm.pathSelected, len(tm.paths))
+	}
+}
+
+func clampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// selectedTag returns the tag under the cursor in the tag list.
+func (tm tagManager) selectedTag() string {
+	if tm.selected < len(tm.tags) {
+		return tm.tags[tm.selected]
+	}
+	return ""
+}
+
+func (m model) updateTagManager(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tm := &m.tagManager
+
+	if tm.renaming {
+		switch msg.String() {
+		case "esc":
+			tm.renaming = false
+			m.tagInput.Blur()
+			m.tagInput.SetValue("")
+		case "enter":
+			newName := strings.TrimPrefix(strings.TrimSpace(m.tagInput.Value()), "@")
+			if tm.err = store.ValidateTagName(newName); tm.err != nil {
+				return m, nil
+			}
+			tm.err = m.db.RenameTag(tm.selectedTag(), newName)
+			tm.renaming = false
+			m.tagInput.Blur()
+			m.tagInput.SetValue("")
+			m.reloadTagManager()
+			for i, t := range tm.tags {
+				if t == newName {
+					tm.selected = i
+				}
+			}
+		default:
+			var cmd tea.Cmd
+			m.tagInput, cmd = m.tagInput.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	if tm.confirmDelete {
+		if msg.String() == "y" {
+			tm.err = m.db.DeleteTag(tm.selectedTag())
+			m.reloadTagManager()
+		}
+		tm.confirmDelete = false
+		return m, nil
+	}
+
+	// Inside a tag: its paths
+	if tm.tag != "" {
+		switch msg.String() {
+		case "esc", "left":
+			tm.tag = ""
+			tm.paths = nil
+			m.reloadTagManager()
+		case "up":
+			if tm.pathSelected > 0 {
+				tm.pathSelected--
+			}
+		case "down":
+			if tm.pathSelected < len(tm.paths)-1 {
+				tm.pathSelected++
+			}
+		case "d":
+			if tm.pathSelected < len(tm.paths) {
+				tm.err = m.db.RemovePathFromTag(tm.tag, tm.paths[tm.pathSelected])
+				m.reloadTagManager()
+			}
+		case "enter":
+			// Re-root the search at the path
+			if tm.pathSelected < len(tm.paths) {
+				m.mode = modeBrowse
+				return m.changeDir(tm.paths[tm.pathSelected])
+			}
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		// Renames and deletions change the tags shown on the results and
+		// may change which paths the active scope holds. Both loads
+		// reload the badges, which searches again.
+		m.mode = modeBrowse
+		if m.activeTag != "" {
+			return m, loadTagFiles(m.db, m.git, m.currentDir, m.tagQuery)
+		}
+		return m, m.loadBadges()
+	case "up":
+		if tm.selected > 0 {
+			tm.selected--
+		}
+	case "down":
+		if tm.selected < len(tm.tags)-1 {
+			tm.selected++
+		}
+	case "enter", "right":
+		if tag := tm.selectedTag(); tag != "" {
+			tm.tag = tag
+			tm.pathSelected = 0
+			m.reloadTagManager()
+		}
+	case "r":
+		if tag := tm.selectedTag(); tag != "" {
+			tm.renaming = true
+			m.tagInput.SetValue(tag)
+			m.tagInput.Focus()
+			m.tagInput.CursorEnd()
+		}
+	case "d":
+		if tm.selectedTag() != "" {
+			tm.confirmDelete = true
+		}
+	}
+	return m, nil
+}
+
+func (m model) tagManagerView() string {
+	tm := m.tagManager
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	styles := m.tagStyles()
+
+	var title, help string
+	var lines []string
+	if tm.tag != "" {
+		title = lipgloss.NewStyle().Bold(true).Render("Tag ") + ui.TagBadge(tm.tag, styles[tm.tag])
+		help = dim.Render("D: remove path • Enter: search here • Esc: back to tags")
+		if len(tm.paths) == 0 {
+			lines = append(lines, "(no paths)")
+		}
+		for i, p := range tm.paths {
+			prefix := "  "
+			if i == tm.pathSelected {
+				prefix = "> "
+			}
+			lines = append(lines, prefix+p)
+		}
+	} else {
+		title = lipgloss.NewStyle().Bold(true).Render("All tags")
+		help = dim.Render("Enter: show paths • R: rename/merge • D: delete • Esc: back")
+		if len(tm.tags) == 0 {
+			lines = append(lines, "(no tags)")
+		}
+		for i, t := range tm.tags {
+			prefix := "  "
+			if i == tm.selected {
+				prefix = "> "
+			}
+			line := fmt.Sprintf("%s%s  %s", prefix, ui.TagBadge(t, styles[t]), detailStyle.Render(fmt.Sprintf("%d paths", tm.counts[t])))
+			if desc := m.tagDefs[t].Description; desc != "" {
+				line += "  " + detailStyle.Render(desc)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	status := ""
+	switch {
+	case tm.renaming:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Render("Rename @"+tm.selectedTag()+" to (an existing tag merges): ") + m.tagInput.View()
+	case tm.confirmDelete:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Render(
+			fmt.Sprintf("Delete @%s from %d paths? y/N", tm.selectedTag(), tm.counts[tm.selectedTag()]))
+	case tm.err != nil:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Render(tm.err.Error())
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		help,
+		strings.Join(lines, "\n"),
+		status,
+	)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/montrey/navi/store"
+)
+
+func TestTagManagerRenameAndDelete(t *testing.T) {
+	db := store.NewMemStore()
+	_ = db.AddPathToTag("old", "/a")
+	_ = db.AddPathToTag("keep", "/b")
+	m := initialModel(db, loadConfig(db)).openTagManager()
+
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "down":
+				msg = tea.KeyMsg{Type: tea.KeyDown}
+			case "ctrl+u":
+				msg = tea.KeyMsg{Type: tea.KeyCtrlU}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			}
+			next, _ := m.updateTagManager(msg)
+			m = next.(model)
+		}
+	}
+
+	// Tags are sorted: keep, old
+	press("down", "r", "ctrl+u", "n", "e", "w", "enter")
+	if tags, _ := db.GetAllTags(); len(tags) != 2 || tags[1] != "new" {
+		t.Fatalf("expected old renamed to new, got %v", tags)
+	}
+	if m.tagManager.selectedTag() != "new" {
+		t.Errorf("expected cursor on the renamed tag, got %q", m.tagManager.selectedTag())
+	}
+
+	// Names a query can't match are refused and the rename stays open
+	press("r", "ctrl+u", "a", ",", "b", "enter")
+	if tags, _ := db.GetAllTags(); !m.tagManager.renaming || m.tagManager.err == nil || tags[1] != "new" {
+		t.Errorf("expected a,b to be refused, got %v (err %v)", tags, m.tagManager.err)
+	}
+	press("esc")
+
+	press("d", "n")
+	if tags, _ := db.GetAllTags(); len(tags) != 2 {
+		t.Errorf("expected delete to need confirmation, got %v", tags)
+	}
+	press("d", "y")
+	if tags, _ := db.GetAllTags(); len(tags) != 1 || tags[0] != "keep" {
+		t.Errorf("expected only keep left, got %v", tags)
+	}
+
+	// Leaving the manager reloads the tags and searches again so the
+	// results show them
+	next, cmd := m.updateTagManager(tea.KeyMsg{Type: tea.KeyEsc})
+	if next.(model).mode != modeBrowse || cmd == nil {
+		t.Fatalf("expected Esc to return to browsing and reload the tags")
+	}
+	badges, ok := cmd().(badgesMsg)
+	if !ok {
+		t.Fatalf("expected the tags to be reloaded, got %T", cmd())
+	}
+	next, cmd = next.Update(badges)
+	if known := next.(model).knownTags; slices.Contains(known, "new") || !slices.Contains(known, "keep") {
+		t.Errorf("expected the deleted tag to be gone, got %v", known)
+	}
+	if _, ok := cmd().(searchDoneMsg); !ok {
+		t.Errorf("expected a search, got %T", cmd())
+	}
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Crumb is one segment of a breadcrumb: its label and the directory it points at.
+type Crumb struct {
+	Label string
+	Path  string
+}
+
+const (
+	crumbSep      = " › "
+	crumbEllipsis = "… › "
+)
+
+// Crumbs splits dir into breadcrumb segments, abbreviating the home directory to "~".
+func Crumbs(dir string) []Crumb {
+	dir = filepath.Clean(dir)
+	var crumbs []Crumb
+
+	rest := dir
+	home, _ := os.UserHomeDir()
+	if home != "" && (dir == home || strings.HasPrefix(dir, home+string(filepath.Separator))) {
+		crumbs = append(crumbs, Crumb{Label: "~", Path: home})
+		rest = strings.TrimPrefix(dir, home)
+	} else if filepath.IsAbs(dir) {
+		crumbs = append(crumbs, Crumb{Label: string(filepath.Separator), Path: string(filepath.Separator)})
+	}
+
+	current := ""
+	if len(crumbs) > 0 {
+		current = crumbs[0].Path
+	}
+	for _, part := range strings.Split(rest, string(filepath.Separator)) {
+		if part == "" || part == "." {
+			continue
+		}
+		current = filepath.Join(current, part)
+		crumbs = append(crumbs, Crumb{Label: part, Path: current})
+	}
+	return crumbs
+}
+
+// visibleCrumbs drops leading segments until the breadcrumb fits in width.
+// It returns the remaining segments and how many were dropped.
+func visibleCrumbs(dir string, width int) ([]Crumb, int) {
+	crumbs := Crumbs(dir)
+	dropped := 0
+	for len(crumbs) > 1 && crumbsWidth(crumbs, dropped > 0) > width {
+		crumbs = crumbs[1:]
+		dropped++
+	}
+	return crumbs, dropped
+}
+
+func crumbsWidth(crumbs []Crumb, trimmed bool) int {
+	w := 0
+	if trimmed {
+		w += lipgloss.Width(crumbEllipsis)
+	}
+	for i, c := range crumbs {
+		if i > 0 {
+			w += lipgloss.Width(crumbSep)
+		}
+		w += lipgloss.Width(c.Label)
+	}
+	return w
+}
+
+// Breadcrumb renders dir as a breadcrumb bar that fits in width. The segment
+// at index selected of Crumbs(dir) is highlighted (-1 for none); a selected
+// segment that didn't fit highlights the leading ellipsis.
+func Breadcrumb(dir string, width, selected int) string {
+	crumbs, dropped := visibleCrumbs(dir, width)
+
+	sepStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	segStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+	lastStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true).Reverse(true)
+
+	var b strings.Builder
+	if dropped > 0 {
+		if selected >= 0 && selected < dropped {
+			b.WriteString(selectedStyle.Render(strings.TrimSuffix(crumbEllipsis, crumbSep)))
+			b.WriteString(sepStyle.Render(crumbSep))
+		} else {
+			b.WriteString(sepStyle.Render(crumbEllipsis))
+		}
+	}
+	for i, c := range crumbs {
+		if i > 0 {
+			b.WriteString(sepStyle.Render(crumbSep))
+		}
+		switch {
+		case i+dropped == selected:
+			b.WriteString(selectedStyle.Render(c.Label))
+		case i == len(crumbs)-1:
+			b.WriteString(lastStyle.Render(c.Label))
+		default:
+			b.WriteString(segStyle.Render(c.Label))
+		}
+	}
+	return b.String()
+}
+
+// BreadcrumbAt returns the directory of the segment rendered at column x of Breadcrumb.
+func BreadcrumbAt(dir string, width, x int) (string, bool) {
+	crumbs, dropped := visibleCrumbs(dir, width)
+	start := 0
+	if dropped > 0 {
+		start = lipgloss.Width(crumbEllipsis)
+	}
+	for i, c := range crumbs {
+		if i > 0 {
+			start += lipgloss.Width(crumbSep)
+		}
+		w := lipgloss.Width(c.Label)
+		if x >= start && x < start+w {
+			return c.Path, true
+		}
+		start += w
+	}
+	return "", false
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCrumbs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := map[string][]Crumb{
+		"/srv/api/": {{"/", "/"}, {"srv", "/srv"}, {"api", "/srv/api"}},
+		"/":         {{"/", "/"}},
+		filepath.Join(home, "src", "navi"): {
+			{"~", home},
+			{"src", filepath.Join(home, "src")},
+			{"navi", filepath.Join(home, "src", "navi")},
+		},
+		home:       {{"~", home}},
+		"rel/path": {{"rel", "rel"}, {"path", "rel/path"}},
+	}
+	for dir, want := range tests {
+		if got := Crumbs(dir); !reflect.DeepEqual(got, want) {
+			t.Errorf("Crumbs(%q) = %v, want %v", dir, got, want)
+		}
+	}
+
+	// A sibling sharing the home prefix isn't abbreviated
+	if got := Crumbs(home + "2"); got[0].Label != "/" {
+		t.Errorf("expected %s2 not to start with ~, got %v", home, got)
+	}
+}
+
+func TestBreadcrumbAt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := "/srv/api/handlers"
+
+	// "/ › srv › api › handlers": columns 4-6 are srv, 10-12 api
+	for x, want := range map[int]string{0: "/", 4: "/srv", 6: "/srv", 10: "/srv/api", 16: "/srv/api/handlers"} {
+		if got, ok := BreadcrumbAt(dir, 80, x); !ok || got != want {
+			t.Errorf("BreadcrumbAt(x=%d) = %q, %v; want %q", x, got, ok, want)
+		}
+	}
+	for _, x := range []int{1, 7, 40} {
+		if got, ok := BreadcrumbAt(dir, 80, x); ok {
+			t.Errorf("expected no segment at x=%d, got %q", x, got)
+		}
+	}
+
+	// Too narrow: "… › api › handlers" drops the leading segments
+	if view := Breadcrumb(dir, 18, -1); !strings.HasPrefix(view, "…") || strings.Contains(view, "srv") {
+		t.Errorf("expected the leading segments to be elided, got %q", view)
+	}
+	if got, ok := BreadcrumbAt(dir, 18, 4); !ok || got != "/srv/api" {
+		t.Errorf("expected api after the ellipsis, got %q, %v", got, ok)
+	}
+	if _, ok := BreadcrumbAt(dir, 18, 0); ok {
+		t.Errorf("expected the ellipsis not to be a segment")
+	}
+}
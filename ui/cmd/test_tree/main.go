@@ -20,7 +20,7 @@ func main() {
 
 	
 	// Create Tree
-	tm := ui.NewTreeModel(paths, 80, 20, make(map[string]bool))
+	tm := ui.NewTreeModel(paths, 80, 20, make(map[string]bool), nil)
 	
 	// Print View
 	fmt.Println("=== Tree Visualization Test ===")
@@ -34,7 +34,7 @@ func main() {
 		"a/b/c/g/h.go",
 		"a/x/y.go",
 	}
-	tm2 := ui.NewTreeModel(paths2, 80, 20, make(map[string]bool))
+	tm2 := ui.NewTreeModel(paths2, 80, 20, make(map[string]bool), nil)
 	fmt.Println("\n=== Deep Tree Test ===")
 	fmt.Println(tm2.View())
 	// Test Compression
@@ -45,7 +45,7 @@ func main() {
 	// "src" -> "main" -> "java" -> "com" -> "example" -> [App, Utils]
 	// Should become: "src/main/java/com/example" -> [App, Utils]
 	
-	tm3 := ui.NewTreeModel(paths3, 80, 20, make(map[string]bool))
+	tm3 := ui.NewTreeModel(paths3, 80, 20, make(map[string]bool), nil)
 	fmt.Println("\n=== Compression Test ===")
 	fmt.Println(tm3.View())
 }
@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/vcs"
+)
+
+// gitStyle colors a git badge: green when everything is staged, red for
+// untracked paths and yellow otherwise.
+func gitStyle(s vcs.State) lipgloss.Style {
+	color := "178" // Yellow
+	switch s {
+	case vcs.Staged:
+		color = "71" // Green
+	case vcs.Untracked:
+		color = "167" // Red
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+}
@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/search"
+	"github.com/montrey/navi/vcs"
+)
+
+// ListItem is a single ranked result in the flat list view.
+type ListItem struct {
+	Path      string
+	Score     int
+	Frecency  float64
+	Tags      []string
+	Matches   []int // Byte offsets of matched characters in Path
+	IsHistory bool
+	Entry     search.Entry // Filesystem details; zero Type if unknown
+	Git       vcs.State    // Git status; zero if clean or unknown
+}
+
+// ListModel renders results as a flat, fzf-like ranked list.
+// Unlike TreeModel it never hides a result behind a collapsed sibling.
+type ListModel struct {
+	Items    []ListItem
+	Selected int
+	Width    int
+	Height   int
+
+	// ScrollOffset is the index of the first visible item
+	ScrollOffset int
+
+	// TagStyles colors tag badges by tag name
+	TagStyles map[string]TagStyle
+}
+
+// NewListModel creates a list model showing at most limit items (0 means no limit).
+func NewListModel(items []ListItem, width, height, limit int) ListModel {
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return ListModel{
+		Items:  items,
+		Width:  width,
+		Height: height,
+	}
+}
+
+func (m ListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ListModel) Update(msg tea.Msg) (ListModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		// Coordinates are relative to the top-left cell of the list view.
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scrollBy(-1)
+			return m, nil
+		case tea.MouseButtonWheelDown:
+			m.scrollBy(1)
+			return m, nil
+		case tea.MouseButtonLeft:
+			if i := m.ItemAt(msg.Y); i >= 0 {
+				m.Selected = i
+			}
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up":
+			m.moveSelection(-1)
+		case "down":
+			m.moveSelection(1)
+		case "pgup":
+			m.moveSelection(-m.Height)
+		case "pgdown":
+			m.moveSelection(m.Height)
+		}
+	}
+	m.clampScroll()
+	return m, nil
+}
+
+func (m *ListModel) moveSelection(delta int) {
+	if len(m.Items) == 0 {
+		return
+	}
+	m.Selected += delta
+	if m.Selected < 0 {
+		m.Selected = 0
+	}
+	if m.Selected >= len(m.Items) {
+		m.Selected = len(m.Items) - 1
+	}
+}
+
+// scrollBy moves ScrollOffset by delta rows without changing the selection.
+func (m *ListModel) scrollBy(delta int) {
+	m.ScrollOffset += delta
+	if m.ScrollOffset > len(m.Items)-m.Height {
+		m.ScrollOffset = len(m.Items) - m.Height
+	}
+	if m.ScrollOffset < 0 {
+		m.ScrollOffset = 0
+	}
+}
+
+// ItemAt returns the index of the item rendered on row y of the list view, or -1.
+func (m ListModel) ItemAt(y int) int {
+	i := m.ScrollOffset + y
+	if y < 0 || y >= m.Height || i >= len(m.Items) {
+		return -1
+	}
+	return i
+}
+
+// clampScroll keeps the selected item inside the visible window.
+func (m *ListModel) clampScroll() {
+	if m.Selected < m.ScrollOffset {
+		m.ScrollOffset = m.Selected
+	}
+	if m.Height > 0 && m.Selected >= m.ScrollOffset+m.Height {
+		m.ScrollOffset = m.Selected - m.Height + 1
+	}
+}
+
+func (m ListModel) View() string {
+	lines := make([]string, 0, m.Height)
+
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	historyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	normalStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+	matchStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+	metaStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	for i := m.ScrollOffset; i < len(m.Items) && len(lines) < m.Height; i++ {
+		item := m.Items[i]
+
+		cursor := "  "
+		style := normalStyle
+		if i == m.Selected {
+			cursor = "> "
+			style = selectedStyle
+		} else if item.IsHistory {
+			style = historyStyle
+		}
+
+		// Score and frecency columns
+		meta := fmt.Sprintf("%5d ", item.Score)
+		if item.Frecency > 0 {
+			meta += fmt.Sprintf("%6.1f ", item.Frecency)
+		} else {
+			meta += fmt.Sprintf("%6s ", "-")
+		}
+
+		suffix := ""
+		if item.Entry.IsDir() {
+			suffix = "/"
+		}
+		if item.Entry.Type == search.TypeSymlink {
+			suffix += linkSuffix(item.Entry)
+		}
+
+		badge := ""
+		if item.Git.Dirty() {
+			badge = " " + gitStyle(item.Git).Render(item.Git.Badge())
+		}
+		if len(item.Tags) > 0 {
+			badge += " " + tagBadges(item.Tags, m.TagStyles)
+		}
+
+		// Truncate the path from the left so the file name stays visible
+		path := item.Path
+		matches := runeIndices(path, item.Matches)
+		room := m.Width - len(cursor) - len(meta) - len(suffix) - lipgloss.Width(badge)
+		if room > 1 && len([]rune(path)) > room {
+			runes := []rune(path)
+			cut := len(runes) - room + 1
+			path = "…" + string(runes[cut:])
+			var shifted []int
+			for _, idx := range matches {
+				if idx >= cut {
+					shifted = append(shifted, idx-cut+1)
+				}
+			}
+			matches = shifted
+		}
+
+		lines = append(lines, style.Render(cursor)+metaStyle.Render(meta)+
+			highlight(path, matches, style, matchStyle)+suffixStyle(item.Entry, style).Render(suffix)+badge)
+	}
+
+	for len(lines) < m.Height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// suffixStyle colors broken symlinks red and keeps other suffixes in the row style.
+func suffixStyle(e search.Entry, base lipgloss.Style) lipgloss.Style {
+	if e.IsBroken() {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
+	}
+	return base
+}
+
+// runeIndices converts byte offsets into s, as sahilm/fuzzy reports
+// matches, into rune indices.
+func runeIndices(s string, offsets []int) []int {
+	if len(offsets) == 0 {
+		return nil
+	}
+	matched := make(map[int]bool, len(offsets))
+	for _, off := range offsets {
+		matched[off] = true
+	}
+	var indices []int
+	n := 0
+	for off := range s {
+		if matched[off] {
+			indices = append(indices, n)
+		}
+		n++
+	}
+	return indices
+}
+
+// highlight renders s with the runes at the given indices in matchStyle.
+func highlight(s string, matches []int, base, matchStyle lipgloss.Style) string {
+	if len(matches) == 0 {
+		return base.Render(s)
+	}
+	matched := make(map[int]bool, len(matches))
+	for _, idx := range matches {
+		matched[idx] = true
+	}
+	var b strings.Builder
+	for i, r := range []rune(s) {
+		if matched[i] {
+			b.WriteString(matchStyle.Render(string(r)))
+		} else {
+			b.WriteString(base.Render(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// SelectedPath returns the path of the selected item.
+func (m ListModel) SelectedPath() string {
+	if m.Selected >= 0 && m.Selected < len(m.Items) {
+		return m.Items[m.Selected].Path
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/montrey/navi/search"
+)
+
+func TestRuneIndices(t *testing.T) {
+	// fuzzy reports byte offsets: in "über/x.go" the "x" is at byte 6, rune 5
+	path := "über/x.go"
+	matches := search.FuzzyHierarchical([]string{path}, "x")
+	if len(matches) != 1 {
+		t.Fatalf("expected one match, got %v", matches)
+	}
+	got := runeIndices(path, matches[0].Matches)
+	if len(got) != 1 || []rune(path)[got[0]] != 'x' {
+		t.Errorf("expected the rune index of x, got %v (from %v)", got, matches[0].Matches)
+	}
+
+	if got := runeIndices("abc", []int{0, 2}); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Errorf("expected ASCII offsets unchanged, got %v", got)
+	}
+}
+
+func TestListPaging(t *testing.T) {
+	var items []ListItem
+	for _, name := range strings.Split("a b c d e f g", " ") {
+		items = append(items, ListItem{Path: name})
+	}
+	m := NewListModel(items, 40, 3, 0)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.Selected != 3 || m.ScrollOffset != 1 {
+		t.Errorf("expected pgdown to select d and scroll it into view, got selected %d offset %d", m.Selected, m.ScrollOffset)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if m.Selected != 0 || m.ScrollOffset != 0 {
+		t.Errorf("expected pgup to return to the top, got selected %d offset %d", m.Selected, m.ScrollOffset)
+	}
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TagStyle is how a tag is drawn in badges and tree markers.
+type TagStyle struct {
+	Color string // lipgloss color; empty uses the default tag color
+	Icon  string // optional glyph shown before the name and used as the tree marker
+}
+
+// defaultTagColor is used for tags without a color of their own.
+const defaultTagColor = "62"
+
+func (s TagStyle) style() lipgloss.Style {
+	color := s.Color
+	if color == "" {
+		color = defaultTagColor
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+}
+
+// TagBadge renders "[@name]" (or "[icon @name]") in the tag's color.
+func TagBadge(name string, s TagStyle) string {
+	label := "@" + name
+	if s.Icon != "" {
+		label = s.Icon + " " + label
+	}
+	return s.style().Render("[" + label + "]")
+}
+
+// tagMarkers returns one marker glyph per tag and the style of each, for
+// drawing tagged nodes in the tree. Tags without an icon use "●".
+func tagMarkers(tags []string, styles map[string]TagStyle) ([]string, []lipgloss.Style) {
+	var glyphs []string
+	var looks []lipgloss.Style
+	for _, t := range tags {
+		s := styles[t]
+		glyph := "●"
+		if s.Icon != "" {
+			glyph = s.Icon
+		}
+		glyphs = append(glyphs, glyph)
+		looks = append(looks, s.style())
+	}
+	return glyphs, looks
+}
+
+// tagBadges renders the badges for tags separated by spaces.
+func tagBadges(tags []string, styles map[string]TagStyle) string {
+	badges := make([]string, 0, len(tags))
+	for _, t := range tags {
+		badges = append(badges, TagBadge(t, styles[t]))
+	}
+	return strings.Join(badges, " ")
+}
@@ -6,6 +6,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/search"
+	"github.com/montrey/navi/vcs"
 )
 
 // Node represents a file or directory in the tree.
@@ -16,9 +18,13 @@ type Node struct {
 	Parent   *Node
 	IsDir    bool
 	IsHistory bool // True if this path is from history
+	Entry    search.Entry // Filesystem details; zero Type if the path was not stat'ed
 
 	// Layout coordinates
 	X, Y int
+
+	// childIndex maps a child's name to the child while the tree is being built
+	childIndex map[string]*Node
 }
 
 // TreeModel handles the tree visualization and navigation.
@@ -33,119 +39,164 @@ type TreeModel struct {
 	
 	// Dynamic Column Widths (Depth -> Max Width)
 	ColWidths map[int]int
+
+	// index maps a node's Path to the node in the current (compressed) tree
+	index map[string]*Node
+	// pool holds the uncompressed nodes of the previous build, keyed by path,
+	// so SetPaths can reuse them instead of reallocating the whole graph
+	pool map[string]*Node
+	// userSelected is set once the user moves the selection; SetPaths then
+	// keeps it instead of jumping to the best match
+	userSelected bool
+
+	// PathTags maps a node Path (see NodePath) to its tags, drawn as markers after the name
+	PathTags map[string][]string
+	// TagStyles colors tag markers by tag name
+	TagStyles map[string]TagStyle
+	// GitStatus maps a node Path (see NodePath) to its git state, drawn as a badge after the name
+	GitStatus map[string]vcs.State
+}
+
+// NodePath returns the Path of the node built for a result path. Absolute
+// paths lose their leading separator ("/home/u/a.go" -> "home/u/a.go").
+func NodePath(path string) string {
+	return filepath.Join(".", path)
 }
 
 // NewTreeModel creates a new tree model from a list of paths.
 // historyPaths is a set of paths that are from history (for visual distinction).
-func NewTreeModel(paths []string, width, height int, historyPaths map[string]bool) TreeModel {
-	root := buildTree(paths, historyPaths)
-	compressTree(root)
+// entries carries the real filesystem type of each path; paths missing from it
+// are treated as directories only if they have children in the result set.
+func NewTreeModel(paths []string, width, height int, historyPaths map[string]bool, entries map[string]search.Entry) TreeModel {
 	tm := TreeModel{
-		Root:      root,
 		Width:     width,
 		Height:    height,
 		ColWidths: make(map[int]int),
 	}
+	tm.SetPaths(paths, historyPaths, entries)
+	return tm
+}
+
+// SetPaths rebuilds the tree for a new result set. Nodes from the previous
+// build are reused, and a selection the user made is kept if its path is
+// still in the results; otherwise the best match (paths[0]) is selected.
+func (m *TreeModel) SetPaths(paths []string, historyPaths map[string]bool, entries map[string]search.Entry) {
+	keep := ""
+	if m.userSelected && m.SelectedNode != nil {
+		keep = m.SelectedNode.Path
+	}
+
+	m.Root = m.buildTree(paths, historyPaths, entries)
+	compressTree(m.Root)
+	m.reindex()
+
+	if n, ok := m.index[keep]; ok && keep != "" {
+		m.SelectedNode = n
+		return
+	}
+	m.userSelected = false
+	m.ScrollOffset = 0
+
 	// Default selection: Best Match (paths[0])
 	if len(paths) > 0 {
-		bestMatch := findNode(root, paths[0])
-		if bestMatch != nil {
-			tm.SelectedNode = bestMatch
-		} else if len(root.Children) > 0 {
-			tm.SelectedNode = root.Children[0]
-		} else {
-			tm.SelectedNode = root
+		if bestMatch, ok := m.index[NodePath(paths[0])]; ok {
+			m.SelectedNode = bestMatch
+			return
 		}
-	} else if len(root.Children) > 0 {
-		tm.SelectedNode = root.Children[0]
+	}
+	if len(m.Root.Children) > 0 {
+		m.SelectedNode = m.Root.Children[0]
 	} else {
-		tm.SelectedNode = root
+		m.SelectedNode = m.Root
 	}
-	return tm
 }
 
-func findNode(root *Node, targetPath string) *Node {
-	// BFS or DFS to find node with Path == targetPath
-	// Since compression updates Path, this works.
-	// Clean targetPath partials?
-	// buildTree uses filepath.Clean via Join?
-	// Let's assume exact match slightly.
-
-	if root.Path == targetPath {
-		return root
-	}
-	// Also check if targetPath is "inside" the root path? (for directories)
-	// But we typically want the exact file match.
-	
-	// Actually targetPath from search is clean.
-	// root.Path is clean.
-	// Simple DFS.
-	stack := []*Node{root}
+// reindex rebuilds the Path -> node index from the compressed tree.
+func (m *TreeModel) reindex() {
+	m.index = make(map[string]*Node, len(m.pool))
+	stack := []*Node{m.Root}
 	for len(stack) > 0 {
 		n := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
-
-		if n.Path == targetPath {
-			return n
+		// Absolute paths produce an unnamed first segment whose Path is also ".";
+		// the root is indexed first and wins.
+		if _, dup := m.index[n.Path]; !dup {
+			m.index[n.Path] = n
 		}
-		// Also check relative?
-		// if path == "./" + targetPath?
-		// buildTree uses "." as root.
-		// if targetPath is "src/main.go", root is ".", child is "src".
-		// child.Path is "src".
-		// Eventually "src/main.go".
-		
 		stack = append(stack, n.Children...)
 	}
-	return nil
 }
 
-func buildTree(paths []string, historyPaths map[string]bool) *Node {
-	root := &Node{Name: "ROOT", IsDir: true, Path: "."}
+// rootKey is the pool key of the root node; it can't collide with a path prefix.
+const rootKey = "\x00"
+
+// node returns the pooled node for key, reset for a new build, or a new one.
+// Keys are the raw path prefixes, since Node.Path drops a leading separator.
+func (m *TreeModel) node(key string, pool map[string]*Node) *Node {
+	n, ok := m.pool[key]
+	if !ok {
+		n = &Node{childIndex: make(map[string]*Node)}
+	}
+	clear(n.childIndex)
+	n.Children = nil
+	n.Parent = nil
+	n.IsDir = false
+	n.IsHistory = false
+	n.Entry = search.Entry{}
+	pool[key] = n
+	return n
+}
+
+func (m *TreeModel) buildTree(paths []string, historyPaths map[string]bool, entries map[string]search.Entry) *Node {
+	pool := make(map[string]*Node, len(m.pool))
+	root := m.node(rootKey, pool)
+	root.Name = "ROOT"
+	root.Path = "."
+	root.IsDir = true
+
 	for _, path := range paths {
 		parts := strings.Split(path, string(filepath.Separator))
 		current := root
+		key := ""
 		for i, part := range parts {
-			var child *Node
-			for _, c := range current.Children {
-				if c.Name == part {
-					child = c
-					break
-				}
+			if i > 0 {
+				key += string(filepath.Separator)
 			}
-			if child == nil {
-				isDir := i < len(parts)-1
+			key += part
+			child, ok := current.childIndex[part]
+			if !ok {
 				childPath := filepath.Join(current.Path, part)
+				child = m.node(key, pool)
+				child.Name = part
+				child.Path = childPath
+				child.Parent = current
 				// Check if this path or any parent is in history
-				isHistory := historyPaths[path] || historyPaths[childPath]
-				child = &Node{
-					Name:      part,
-					Path:      childPath,
-					Parent:    current,
-					IsDir:     isDir,
-					IsHistory: isHistory,
-				}
+				child.IsHistory = historyPaths[path] || historyPaths[childPath]
+				current.childIndex[part] = child
+				// Children keep insertion order to preserve search relevance
 				current.Children = append(current.Children, child)
-				// Sort removed to preserve search relevance order
-				/*
-				sort.Slice(current.Children, func(i, j int) bool {
-					return current.Children[i].Name < current.Children[j].Name
-				})
-				*/
-			} else {
+			} else if historyPaths[path] || historyPaths[child.Path] {
 				// If this path is in history, mark the existing node too
-				if historyPaths[path] || historyPaths[child.Path] {
-					child.IsHistory = true
-				}
+				child.IsHistory = true
 			}
 			current = child
-			current.IsDir = true
+			if i < len(parts)-1 {
+				current.IsDir = true
+			}
 		}
 		// Mark the final node as history if the path is in history
 		if historyPaths[path] {
 			current.IsHistory = true
 		}
+		if e, ok := entries[path]; ok && e.Type != search.TypeUnknown {
+			current.Entry = e
+			if e.IsDir() {
+				current.IsDir = true
+			}
+		}
 	}
+
+	m.pool = pool
 	return root
 }
 
@@ -191,6 +242,7 @@ func compressTree(node *Node) {
 		node.Name = filepath.Join(node.Name, child.Name)
 		node.Path = child.Path
 		node.IsDir = child.IsDir
+		node.Entry = child.Entry
 		node.Children = child.Children
 		
 		// CRITICAL: Update Parent pointers for grandchildren!
@@ -220,6 +272,24 @@ func (m TreeModel) Init() tea.Cmd {
 
 func (m TreeModel) Update(msg tea.Msg) (TreeModel, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		// Coordinates are relative to the top-left cell of the tree view.
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scrollBy(-1)
+			return m, nil
+		case tea.MouseButtonWheelDown:
+			m.scrollBy(1)
+			return m, nil
+		case tea.MouseButtonLeft:
+			if n := m.NodeAt(msg.X, msg.Y); n != nil {
+				m.SelectedNode = n
+				m.userSelected = true
+			}
+		}
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "up", "k":
@@ -230,7 +300,10 @@ func (m TreeModel) Update(msg tea.Msg) (TreeModel, tea.Cmd) {
 			m.enterDirectory()
 		case "left", "h":
 			m.leaveDirectory()
+		default:
+			return m, nil
 		}
+		m.userSelected = true
 	}
 
 	// Adjust scroll to keep selection in view
@@ -270,6 +343,45 @@ func (m TreeModel) Update(msg tea.Msg) (TreeModel, tea.Cmd) {
 	return m, nil
 }
 
+// scrollBy moves ScrollOffset by delta rows without changing the selection.
+func (m *TreeModel) scrollBy(delta int) {
+	if m.Root == nil || m.SelectedNode == nil {
+		return
+	}
+	rows := m.layoutRoot(m.Root, m.getExpandedMap())
+	m.ScrollOffset += delta
+	if m.ScrollOffset > rows-m.Height {
+		m.ScrollOffset = rows - m.Height
+	}
+	if m.ScrollOffset < 0 {
+		m.ScrollOffset = 0
+	}
+}
+
+// NodeAt returns the node rendered at cell (x, y) of the tree view, or nil.
+func (m TreeModel) NodeAt(x, y int) *Node {
+	if m.Root == nil || m.SelectedNode == nil || y < 0 || y >= m.Height {
+		return nil
+	}
+	expanded, scrollX := m.screenLayout()
+
+	var hit *Node
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		if n.X >= scrollX && n.Y-m.ScrollOffset == y {
+			screenX := m.columnX(n.X, scrollX)
+			if x >= screenX && x < screenX+m.ColWidths[n.X] {
+				hit = n
+			}
+		}
+		for _, child := range m.getVisibleChildren(n, expanded) {
+			visit(child)
+		}
+	}
+	visit(m.Root)
+	return hit
+}
+
 func (m *TreeModel) moveSelection(delta int) {
 	if m.SelectedNode == nil || m.SelectedNode.Parent == nil {
 		return
@@ -356,8 +468,8 @@ func (m TreeModel) getVisibleChildren(node *Node, expanded map[*Node]bool) []*No
 	return node.Children
 }
 
-// layoutRoot calculates X, Y for all nodes
-func (m *TreeModel) layoutRoot(root *Node, expanded map[*Node]bool) {
+// layoutRoot calculates X, Y for all nodes and returns the number of rows used.
+func (m *TreeModel) layoutRoot(root *Node, expanded map[*Node]bool) int {
 	// 1. Assign Y to leaves
 	// 2. Assign Y to parents (center of children)
 	// 3. Assign X based on depth
@@ -369,6 +481,14 @@ func (m *TreeModel) layoutRoot(root *Node, expanded map[*Node]bool) {
 	
 	// Start layout
 	layoutAssign(root, 0, yCounters, expanded, m)
+
+	rows := 0
+	for _, n := range yCounters {
+		if n > rows {
+			rows = n
+		}
+	}
+	return rows
 }
 
 func layoutAssign(node *Node, depth int, yCounters map[int]int, expanded map[*Node]bool, m *TreeModel) {
@@ -389,6 +509,9 @@ func layoutAssign(node *Node, depth int, yCounters map[int]int, expanded map[*No
     // Duplicate logic or simplify?
     // Let's approximate: len(node.Name) + 2 (cursor) + 1 (slash)
     w := len(name) + 4 // Cursor "> " + "/" + padding
+    if node.Entry.Type == search.TypeSymlink {
+        w += len(linkSuffix(node.Entry))
+    }
     
     // If compressed, name might change visually (…/parent/child).
     if node != m.SelectedNode && strings.Contains(name, string(filepath.Separator)) {
@@ -443,11 +566,9 @@ func layoutAssign(node *Node, depth int, yCounters map[int]int, expanded map[*No
     }
 }
 
-func (m TreeModel) View() string {
-	if m.Root == nil {
-		return ""
-	}
-
+// screenLayout runs a layout pass and shifts X so the hidden display root sits
+// left of the screen. It returns the expanded set and the first visible column.
+func (m *TreeModel) screenLayout() (map[*Node]bool, int) {
 	// 1. Calculate Layout
 	// We want to hide "Common Ancestors" if they are just single-child containers.
 	// Find the "Display Root": The first node that has > 1 child OR is a leaf?
@@ -549,6 +670,26 @@ func (m TreeModel) View() string {
 	        scrollX++
 	    }
 	}
+
+	return expanded, scrollX
+}
+
+// columnX returns the screen X of a logical column given the first visible column.
+func (m *TreeModel) columnX(col, scrollX int) int {
+	x := 0
+	for i := scrollX; i < col; i++ {
+		x += m.ColWidths[i]
+	}
+	return x
+}
+
+func (m TreeModel) View() string {
+	if m.Root == nil {
+		return ""
+	}
+
+	// 1. Calculate Layout (display root, X shift, horizontal scroll)
+	expanded, scrollX := m.screenLayout()
 	
 	// 4. Render Canvas
 	// We only render the visible window (m.ScrollOffset to m.ScrollOffset + m.Height)
@@ -576,11 +717,27 @@ func (m TreeModel) View() string {
 			return
 		}
 
-		runes := []rune(s)
-		for i, r := range runes {
-			if x+i >= 0 && x+i < m.Width {
-				canvas[screenY][x+i] = style.Render(string(r))
+		// A wide rune fills its cell and blanks the next one, so every
+		// cell stays one column on screen
+		line := canvas[screenY]
+		for _, r := range s {
+			w := lipgloss.Width(string(r))
+			if w == 0 {
+				continue
+			}
+			if x >= 0 && x+w <= m.Width {
+				if line[x] == "" && x > 0 {
+					line[x-1] = " " // Overwriting the second half of a wide rune
+				}
+				if x+w < m.Width && line[x+w] == "" {
+					line[x+w] = " " // Overwriting the first half of one
+				}
+				line[x] = style.Render(string(r))
+				for i := 1; i < w; i++ {
+					line[x+i] = ""
+				}
 			}
+			x += w
 		}
 	}
 
@@ -656,13 +813,44 @@ func (m TreeModel) View() string {
 			if n.IsDir {
 				name += "/"
 			}
+			if n.Entry.Type == search.TypeSymlink {
+				name += linkSuffix(n.Entry)
+				if n.Entry.IsBroken() && n != m.SelectedNode {
+					style = style.Foreground(lipgloss.Color("160")) // Red
+				}
+			}
 			
+			glyphs, looks := tagMarkers(m.PathTags[n.Path], m.TagStyles)
+			git := m.GitStatus[n.Path]
+			glyphsWidth := 0
+			for _, g := range glyphs {
+				glyphsWidth += lipgloss.Width(g)
+			}
+			room := colWidth - 2
+			if len(glyphs) > 0 {
+				room -= glyphsWidth + 1
+			}
+			if git.Dirty() {
+				room -= lipgloss.Width(git.Badge()) + 1
+			}
+
 			// Truncate to column length (safe guard)
-			if len(name) > colWidth-2 {
-				name = name[:colWidth-2] + ".."
-			} 
+			if room > 0 && lipgloss.Width(name) > room {
+				name = truncateWidth(name, room) + ".."
+			}
 	
 			drawString(screenX, n.Y, cursor+name, style)
+			markerX := screenX + lipgloss.Width(cursor+name) + 1
+			for i, g := range glyphs {
+				drawString(markerX, n.Y, g, looks[i])
+				markerX += lipgloss.Width(g)
+			}
+			if git.Dirty() {
+				if len(glyphs) > 0 {
+					markerX++
+				}
+				drawString(markerX, n.Y, git.Badge(), gitStyle(git))
+			}
 		}
 		
 		// Draw Connectors to Children
@@ -745,6 +933,14 @@ func (m TreeModel) View() string {
 	return s.String()
 }
 
+// linkSuffix renders the symlink arrow, or a broken marker if the target is gone.
+func linkSuffix(e search.Entry) string {
+	if e.IsBroken() {
+		return " -> " + e.Target + " (broken)"
+	}
+	return " -> " + e.Target
+}
+
 // applyXShift recursively shifts X
 func (m *TreeModel) applyXShift(node *Node, shift int) {
 	node.X += shift
@@ -753,9 +949,25 @@ func (m *TreeModel) applyXShift(node *Node, shift int) {
 	}
 }
 
+// SelectedIsDir reports whether the selected node is a directory.
+func (m TreeModel) SelectedIsDir() bool {
+	return m.SelectedNode != nil && m.SelectedNode.IsDir
+}
+
 func (m TreeModel) SelectedPath() string {
 	if m.SelectedNode != nil {
 		return strings.TrimPrefix(m.SelectedNode.Path, "ROOT/") // Clean prefix if exists
 	}
 	return ""
 }
+
+// truncateWidth cuts s to at most width terminal columns.
+func truncateWidth(s string, width int) string {
+	w := 0
+	for i, r := range s {
+		if w += lipgloss.Width(string(r)); w > width {
+			return s[:i]
+		}
+	}
+	return s
+}
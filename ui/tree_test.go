@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/montrey/navi/vcs"
+)
+
+func TestSetPathsKeepsSelection(t *testing.T) {
+	paths := []string{
+		"src/main.go",
+		"src/utils/helper.go",
+		"src/utils/string.go",
+		"README.md",
+	}
+	tm := NewTreeModel(paths, 80, 20, make(map[string]bool), nil)
+	if got := tm.SelectedPath(); got != "src/main.go" {
+		t.Fatalf("expected best match src/main.go to be selected, got %s", got)
+	}
+
+	// Without a user selection, the new best match is followed
+	tm.SetPaths([]string{"README.md", "src/main.go"}, make(map[string]bool), nil)
+	if got := tm.SelectedPath(); got != "README.md" {
+		t.Errorf("expected new best match README.md, got %s", got)
+	}
+
+	// Once the user moves the selection, it survives a rebuild
+	tm.SetPaths(paths, make(map[string]bool), nil)
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	selected := tm.SelectedPath()
+	if selected == "src/main.go" {
+		t.Fatalf("expected selection to move off src/main.go")
+	}
+	oldNode := tm.SelectedNode
+
+	tm.SetPaths([]string{"src/main.go", "src/utils/helper.go", "src/utils/string.go"}, make(map[string]bool), nil)
+	if got := tm.SelectedPath(); got != selected {
+		t.Errorf("expected selection %s to be kept, got %s", selected, got)
+	}
+	if tm.SelectedNode != oldNode {
+		t.Errorf("expected the selected node to be reused across rebuilds")
+	}
+
+	// If the selected path disappears, fall back to the best match
+	tm.SetPaths([]string{"src/main.go"}, make(map[string]bool), nil)
+	if got := tm.SelectedPath(); got != "src/main.go" {
+		t.Errorf("expected fallback to src/main.go, got %s", got)
+	}
+}
+
+func TestBuildTreeAbsolutePaths(t *testing.T) {
+	paths := []string{
+		"/home/user/a.go",
+		"/home/user/b.go",
+		"/tmp/c.go",
+	}
+	tm := NewTreeModel(paths, 80, 20, make(map[string]bool), nil)
+	if got := tm.SelectedPath(); got != "home/user/a.go" {
+		t.Errorf("expected home/user/a.go to be selected, got %s", got)
+	}
+	if tm.Root.Parent != nil || tm.Root.Name != "ROOT" {
+		t.Errorf("expected root to stay distinct from the unnamed first segment")
+	}
+}
+
+func TestViewMarkersAbsolutePaths(t *testing.T) {
+	paths := []string{"/home/u/proj/a.go", "/home/u/proj/b.go"}
+	tm := NewTreeModel(paths, 80, 10, make(map[string]bool), nil)
+	tm.PathTags = map[string][]string{NodePath(paths[0]): {"work"}}
+	tm.GitStatus = map[string]vcs.State{NodePath(paths[1]): vcs.Modified}
+
+	var aLine, bLine string
+	for _, line := range strings.Split(tm.View(), "\n") {
+		if strings.Contains(line, "a.go") {
+			aLine = line
+		}
+		if strings.Contains(line, "b.go") {
+			bLine = line
+		}
+	}
+	if !strings.Contains(aLine, "●") {
+		t.Errorf("expected a tag marker after a.go, got %q", aLine)
+	}
+	if !strings.Contains(bLine, "b.go M") {
+		t.Errorf("expected a git badge after b.go, got %q", bLine)
+	}
+}
+
+func TestViewWideMarkers(t *testing.T) {
+	paths := []string{"/home/u/proj/a.go", "/home/u/proj/b.go"}
+	tm := NewTreeModel(paths, 60, 10, make(map[string]bool), nil)
+	tm.PathTags = map[string][]string{NodePath(paths[0]): {"work", "home"}}
+	tm.TagStyles = map[string]TagStyle{"work": {Icon: "🚀"}, "home": {Icon: "家"}}
+	tm.GitStatus = map[string]vcs.State{NodePath(paths[0]): vcs.Modified}
+
+	for _, line := range strings.Split(tm.View(), "\n") {
+		if w := lipgloss.Width(line); w != 60 {
+			t.Errorf("expected every line to be 60 columns, got %d: %q", w, line)
+		}
+		if strings.Contains(line, "a.go") && !strings.Contains(line, "a.go 🚀家 M") {
+			t.Errorf("expected the markers and git badge side by side, got %q", line)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package vcs
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is the git status of a path; directories carry the union of the
+// states of the changed files below them.
+type State uint8
+
+const (
+	Staged State = 1 << iota
+	Modified
+	Untracked
+)
+
+// Dirty reports whether the path has any change.
+func (s State) Dirty() bool { return s != 0 }
+
+// Badge returns a short marker per change kind: "+" staged, "M" modified, "?" untracked.
+func (s State) Badge() string {
+	var b strings.Builder
+	if s&Staged != 0 {
+		b.WriteString("+")
+	}
+	if s&Modified != 0 {
+		b.WriteString("M")
+	}
+	if s&Untracked != 0 {
+		b.WriteString("?")
+	}
+	return b.String()
+}
+
+// DefaultTTL is how long a repository's status is reused before git is asked again.
+const DefaultTTL = 10 * time.Second
+
+type repoStatus struct {
+	files   map[string]State // Changed files (absolute) and their ancestor dirs up to the root
+	changed []string         // Changed files, sorted
+	loaded  time.Time
+}
+
+// Cache runs `git status --porcelain` at most once per repository per TTL.
+// It is safe for concurrent use.
+type Cache struct {
+	TTL time.Duration
+
+	mu    sync.Mutex
+	roots map[string]string // Directory -> repository root ("" if none)
+	repos map[string]*repoStatus
+}
+
+// NewCache returns an empty cache using DefaultTTL.
+func NewCache() *Cache {
+	return &Cache{
+		TTL:   DefaultTTL,
+		roots: make(map[string]string),
+		repos: make(map[string]*repoStatus),
+	}
+}
+
+// Available reports whether a git binary is on PATH.
+func Available() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+// RepoRoot returns the repository containing path (the nearest ancestor
+// with a .git entry, path included), remembering the answer for every path
+// it checked.
+func (c *Cache) RepoRoot(path string) (string, bool) {
+	dir := filepath.Clean(path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var checked []string
+	root := ""
+	for {
+		if r, ok := c.roots[dir]; ok {
+			root = r
+			break
+		}
+		checked = append(checked, dir)
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			root = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, d := range checked {
+		c.roots[d] = root
+	}
+	return root, root != ""
+}
+
+// Fresh reports whether repo's status is cached and younger than the TTL.
+func (c *Cache) Fresh(repo string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.repos[repo]
+	return ok && time.Since(st.loaded) < c.TTL
+}
+
+// Refresh runs git status in repo and caches the result, reporting whether
+// any path's state differs from the cached one (a repo seen for the first
+// time changed if it has changes). A failing repo is cached as clean so it is
+// not retried before the TTL runs out.
+func (c *Cache) Refresh(repo string) (bool, error) {
+	out, err := exec.Command("git", "-C", repo, "status", "--porcelain=v1", "-z").Output()
+	st := &repoStatus{files: make(map[string]State)}
+	if err == nil {
+		st = parsePorcelain(repo, out)
+	}
+	st.loaded = time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := len(st.files) > 0
+	if old, ok := c.repos[repo]; ok {
+		changed = !maps.Equal(old.files, st.files)
+	}
+	c.repos[repo] = st
+	return changed, err
+}
+
+// Peek returns the cached state of path without running git. Paths inside
+// an untracked directory are untracked.
+func (c *Cache) Peek(path string) State {
+	repo, ok := c.RepoRoot(path)
+	if !ok {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.repos[repo]
+	if !ok {
+		return 0
+	}
+	if s, ok := st.files[path]; ok {
+		return s
+	}
+	for dir := filepath.Dir(path); len(dir) > len(repo); dir = filepath.Dir(dir) {
+		if st.files[dir+string(filepath.Separator)]&Untracked != 0 {
+			return Untracked
+		}
+	}
+	return 0
+}
+
+// Changed returns the changed files of repo, refreshing it if stale.
+func (c *Cache) Changed(repo string) ([]string, error) {
+	if !c.Fresh(repo) {
+		if _, err := c.Refresh(repo); err != nil {
+			return nil, err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.repos[repo].changed, nil
+}
+
+// parsePorcelain reads `git status --porcelain=v1 -z` output. Each record is
+// "XY path"; renames and copies are followed by the original path.
+func parsePorcelain(repo string, out []byte) *repoStatus {
+	st := &repoStatus{files: make(map[string]State)}
+	records := bytes.Split(out, []byte{0})
+	for i := 0; i < len(records); i++ {
+		rec := string(records[i])
+		if len(rec) < 4 {
+			continue
+		}
+		x, y, rel := rec[0], rec[1], rec[3:]
+		if x == 'R' || x == 'C' {
+			i++ // Skip the original path
+		}
+
+		var s State
+		switch {
+		case x == '?' && y == '?':
+			s = Untracked
+		default:
+			if x != ' ' {
+				s |= Staged
+			}
+			if y != ' ' {
+				s |= Modified
+			}
+		}
+
+		// Untracked directories are reported with a trailing slash
+		isDir := strings.HasSuffix(rel, "/")
+		path := filepath.Join(repo, filepath.FromSlash(rel))
+		st.changed = append(st.changed, path)
+		st.files[path] |= s
+		if isDir {
+			// Marks path as an untracked directory for Peek
+			st.files[path+string(filepath.Separator)] |= s
+		}
+		for dir := filepath.Dir(path); len(dir) >= len(repo); dir = filepath.Dir(dir) {
+			st.files[dir] |= s
+			if dir == repo {
+				break
+			}
+		}
+	}
+	sort.Strings(st.changed)
+	return st
+}
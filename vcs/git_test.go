@@ -0,0 +1,93 @@
+package vcs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePorcelain(t *testing.T) {
+	out := []byte("M  staged.go\x00 M sub/mod.go\x00MM both.go\x00R  new.go\x00old.go\x00?? tmp/\x00")
+	st := parsePorcelain("/repo", out)
+
+	cases := map[string]State{
+		"/repo/staged.go":  Staged,
+		"/repo/sub/mod.go": Modified,
+		"/repo/both.go":    Staged | Modified,
+		"/repo/new.go":     Staged,
+		"/repo/sub":        Modified,
+		"/repo/tmp":        Untracked,
+		"/repo":            Staged | Modified | Untracked,
+	}
+	for path, want := range cases {
+		if got := st.files[path]; got != want {
+			t.Errorf("%s: expected %q, got %q", path, want.Badge(), got.Badge())
+		}
+	}
+	if _, ok := st.files["/repo/old.go"]; ok {
+		t.Errorf("expected the rename source to be skipped")
+	}
+	if len(st.changed) != 5 {
+		t.Errorf("expected 5 changed paths, got %v", st.changed)
+	}
+}
+
+func TestCache(t *testing.T) {
+	if !Available() {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) string {
+		p := filepath.Join(repo, name)
+		_ = os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	git("init", "-q")
+	clean := write("clean.txt", "a")
+	mod := write("mod.txt", "a")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	write("mod.txt", "b")
+	scratch := write("scratch/notes.txt", "x")
+
+	c := NewCache()
+	root, ok := c.RepoRoot(mod)
+	if !ok || root != repo {
+		t.Fatalf("expected repo root %s, got %q", repo, root)
+	}
+	if c.Peek(mod) != 0 {
+		t.Errorf("expected Peek to report nothing before a refresh")
+	}
+	changed, err := c.Changed(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 2 {
+		t.Errorf("expected mod.txt and scratch/ to be changed, got %v", changed)
+	}
+	if c.Peek(mod) != Modified || c.Peek(clean) != 0 || c.Peek(scratch) != Untracked {
+		t.Errorf("unexpected states: mod %q clean %q scratch %q", c.Peek(mod).Badge(), c.Peek(clean).Badge(), c.Peek(scratch).Badge())
+	}
+	if !c.Fresh(repo) {
+		t.Errorf("expected the status to be cached")
+	}
+
+	if changed, _ := c.Refresh(repo); changed {
+		t.Errorf("expected an unchanged repo to refresh as unchanged")
+	}
+	write("clean.txt", "b")
+	if changed, _ := c.Refresh(repo); !changed || c.Peek(clean) != Modified {
+		t.Errorf("expected the refresh to report the new modification")
+	}
+}